@@ -0,0 +1,172 @@
+// Package cards renders Lark interactive cards from declarative templates.
+//
+// A template names a header, Markdown text, label/value fields, buttons and a footer note.
+// Any string may hold {{placeholders}} filled from request data; placeholders may use dots
+// to reach into nested objects, e.g. {{task.url}}. Text, fields, buttons and notes that
+// render empty are left out, so one template serves cards with and without optional data.
+package cards
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-yaml"
+)
+
+//go:embed templates/*.yaml
+var builtinFS embed.FS
+
+type Template struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Header      Header   `json:"header"`
+	Text        string   `json:"text,omitempty"` // Markdown body
+	Fields      []Field  `json:"fields,omitempty"`
+	Buttons     []Button `json:"buttons,omitempty"`
+	Note        string   `json:"note,omitempty"` // Footer in small print
+	Source      string   `json:"source"`         // "builtin" or the file it was loaded from
+}
+
+type Header struct {
+	Title string `json:"title"`
+	Color string `json:"color,omitempty"` // Lark header template: "blue", "wathet", "turquoise", "green", "yellow", "orange", "red", "carmine", "violet", "purple", "indigo", "grey"
+}
+
+type Field struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+	Wide  bool   `json:"wide,omitempty"` // Take the full width instead of half
+}
+
+type Button struct {
+	Text  string            `json:"text"`
+	URL   string            `json:"url,omitempty"`   // Link action
+	Type  string            `json:"type,omitempty"`  // "default", "primary" or "danger"
+	Value map[string]string `json:"value,omitempty"` // Callback payload; needs a card callback URL configured for the app, which this service doesn't provide
+}
+
+// Registry holds templates by name. Templates from a directory override built-in ones of the same name.
+type Registry struct {
+	mu        sync.RWMutex
+	templates map[string]*Template
+}
+
+// NewRegistry loads the built-in templates and then every .yaml, .yml and .json file in dir, if dir is set.
+// Files that fail to load are reported in the error; the registry still holds everything else.
+func NewRegistry(dir string) (*Registry, error) {
+	r := &Registry{templates: map[string]*Template{}}
+
+	entries, err := builtinFS.ReadDir("templates")
+	if err != nil {
+		return r, err
+	}
+	for _, e := range entries {
+		data, err := builtinFS.ReadFile("templates/" + e.Name())
+		if err != nil {
+			return r, err
+		}
+		t, err := parseTemplate(e.Name(), data)
+		if err != nil {
+			return r, fmt.Errorf("builtin %s: %w", e.Name(), err)
+		}
+		t.Source = "builtin"
+		r.templates[t.Name] = t
+	}
+
+	if dir == "" {
+		return r, nil
+	}
+	return r, r.LoadDir(dir)
+}
+
+// LoadDir adds or replaces templates from the files in dir
+func (r *Registry) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, e := range entries {
+		ext := strings.ToLower(filepath.Ext(e.Name()))
+		if e.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+
+		p := filepath.Join(dir, e.Name())
+		data, err := os.ReadFile(p)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		t, err := parseTemplate(e.Name(), data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p, err))
+			continue
+		}
+		t.Source = p
+
+		r.mu.Lock()
+		r.templates[t.Name] = t
+		r.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// Get returns the template with the given name
+func (r *Registry) Get(name string) (*Template, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.templates[name]
+	return t, ok
+}
+
+// List returns every template, sorted by name
+func (r *Registry) List() []*Template {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]*Template, 0, len(r.templates))
+	for _, t := range r.templates {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// parseTemplate decodes a template file. The name defaults to the file name without extension.
+func parseTemplate(fileName string, data []byte) (*Template, error) {
+	var t Template
+	var err error
+	if strings.EqualFold(filepath.Ext(fileName), ".json") {
+		err = json.Unmarshal(data, &t)
+	} else {
+		err = yaml.Unmarshal(data, &t)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Name == "" {
+		t.Name = strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	}
+	if t.Header.Title == "" {
+		return nil, errors.New("header.title is required")
+	}
+	for i, b := range t.Buttons {
+		if b.Text == "" {
+			return nil, fmt.Errorf("button %d has no text", i)
+		}
+		if b.URL == "" && len(b.Value) == 0 {
+			return nil, fmt.Errorf("button %q needs a url or a value", b.Text)
+		}
+	}
+	return &t, nil
+}
@@ -0,0 +1,299 @@
+package cards
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	data := map[string]interface{}{
+		"name":  "Ann",
+		"count": 3.0,
+		"ratio": 0.5,
+		"done":  true,
+		"task":  map[string]interface{}{"url": "https://example.com/t/1", "owner": map[string]interface{}{"name": "Bob"}},
+		"tags":  []interface{}{"a", 2.0, true},
+		"meta":  map[string]interface{}{"k": "v"},
+		"none":  nil,
+	}
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"name", "Ann"},
+		{"count", "3"},
+		{"ratio", "0.5"},
+		{"done", "true"},
+		{"task.url", "https://example.com/t/1"},
+		{"task.owner.name", "Bob"},
+		{"tags", "a, 2, true"},
+		{"meta", `{"k":"v"}`},
+		{"none", ""},
+		{"missing", ""},
+		{"task.missing", ""},
+		{"name.first", ""},
+		{"task.url.host", ""},
+	}
+	for _, tt := range tests {
+		if got := lookup(data, tt.key); got != tt.want {
+			t.Errorf("lookup(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestFill(t *testing.T) {
+	data := map[string]interface{}{
+		"name": "Ann",
+		"task": map[string]interface{}{"id": 7.0},
+	}
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Hello {{name}}", "Hello Ann"},
+		{"Hello {{ name }}", "Hello Ann"},
+		{"Task #{{task.id}} for {{name}}", "Task #7 for Ann"},
+		{"Hi {{missing}}!", "Hi !"},
+		{"No placeholders", "No placeholders"},
+		{"{{not a placeholder}}", "{{not a placeholder}}"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := fill(tt.in, data); got != tt.want {
+			t.Errorf("fill(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	tmpl := &Template{
+		Header: Header{Title: "{{title}}", Color: "blue"},
+		Text:   "{{body}}",
+		Fields: []Field{
+			{Label: "Owner", Value: "{{owner}}"},
+			{Label: "Notes", Value: "{{notes}}", Wide: true},
+		},
+		Buttons: []Button{
+			{Text: "Open", URL: "{{url}}", Type: "primary"},
+			{Text: "Diff", URL: "{{diff_url}}"},
+			{Text: "Ack", Value: map[string]string{"id": "{{id}}"}},
+		},
+		Note: "{{note}}",
+	}
+
+	card, err := tmpl.Render(map[string]interface{}{
+		"title": "Hello",
+		"owner": "Ann",
+		"url":   "https://example.com",
+		"id":    "42",
+	})
+	if err != nil {
+		t.Fatalf("Render error: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"config": map[string]interface{}{"wide_screen_mode": true, "update_multi": true},
+		"header": map[string]interface{}{
+			"title":    map[string]interface{}{"tag": "plain_text", "content": "Hello"},
+			"template": "blue",
+		},
+		"elements": []interface{}{
+			map[string]interface{}{"tag": "div", "fields": []interface{}{
+				map[string]interface{}{
+					"is_short": true,
+					"text":     map[string]interface{}{"tag": "lark_md", "content": "**Owner**\nAnn"},
+				},
+			}},
+			map[string]interface{}{"tag": "action", "actions": []interface{}{
+				map[string]interface{}{
+					"tag":  "button",
+					"text": map[string]interface{}{"tag": "plain_text", "content": "Open"},
+					"type": "primary",
+					"url":  "https://example.com",
+				},
+				map[string]interface{}{
+					"tag":   "button",
+					"text":  map[string]interface{}{"tag": "plain_text", "content": "Ack"},
+					"type":  "default",
+					"value": map[string]string{"id": "42"},
+				},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(card, want) {
+		t.Errorf("Render = %#v, want %#v", card, want)
+	}
+}
+
+func TestRenderEmpty(t *testing.T) {
+	tmpl := &Template{
+		Header:  Header{Title: "{{title}}"},
+		Text:    "{{body}}",
+		Fields:  []Field{{Label: "Owner", Value: "{{owner}}"}},
+		Buttons: []Button{{Text: "Open", URL: "{{url}}"}},
+		Note:    "{{note}}",
+	}
+
+	card, err := tmpl.Render(map[string]interface{}{"title": "Only a title"})
+	if err != nil {
+		t.Fatalf("Render error: %v", err)
+	}
+	if elements := card["elements"].([]interface{}); len(elements) != 0 {
+		t.Errorf("elements = %#v, want none", elements)
+	}
+	if _, ok := card["header"].(map[string]interface{})["template"]; ok {
+		t.Error("a header without a color should have no template")
+	}
+
+	if _, err := tmpl.Render(map[string]interface{}{"title": "  "}); err == nil {
+		t.Error("a title that renders blank should be an error")
+	}
+	if _, err := tmpl.Render(nil); err == nil {
+		t.Error("a title that renders empty should be an error")
+	}
+}
+
+func TestParseTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		data     string
+		wantName string
+		wantErr  bool
+	}{
+		{name: "yaml", fileName: "a.yaml", data: "name: greeting\nheader:\n  title: Hi\n", wantName: "greeting"},
+		{name: "json", fileName: "a.json", data: `{"name": "greeting", "header": {"title": "Hi"}}`, wantName: "greeting"},
+		{name: "json extension case", fileName: "a.JSON", data: `{"header": {"title": "Hi"}}`, wantName: "a"},
+		{name: "name from file", fileName: "welcome.yml", data: "header:\n  title: Hi\n", wantName: "welcome"},
+		{name: "value button", fileName: "a.yaml", data: "header:\n  title: Hi\nbuttons:\n  - text: Ack\n    value:\n      id: \"1\"\n", wantName: "a"},
+		{name: "no title", fileName: "a.yaml", data: "name: a\n", wantErr: true},
+		{name: "button without text", fileName: "a.yaml", data: "header:\n  title: Hi\nbuttons:\n  - url: https://example.com\n", wantErr: true},
+		{name: "button without action", fileName: "a.yaml", data: "header:\n  title: Hi\nbuttons:\n  - text: Open\n", wantErr: true},
+		{name: "invalid json", fileName: "a.json", data: "{", wantErr: true},
+		{name: "invalid yaml", fileName: "a.yaml", data: "header: [", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseTemplate(tt.fileName, []byte(tt.data))
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: parseTemplate = %#v, want an error", tt.name, got)
+			}
+			continue
+		}
+		if err != nil || got.Name != tt.wantName {
+			t.Errorf("%s: parseTemplate = %#v, %v, want name %q", tt.name, got, err, tt.wantName)
+		}
+	}
+}
+
+func TestBuiltinTemplates(t *testing.T) {
+	r, err := NewRegistry("")
+	if err != nil {
+		t.Fatalf("NewRegistry error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		variables []string
+		data      map[string]interface{}
+		title     string
+		buttons   int
+	}{
+		{
+			name:      "approval_needed",
+			variables: []string{"approve_url", "approver", "deadline", "description", "note", "reject_url", "requester", "title", "url"},
+			data:      map[string]interface{}{"title": "Budget", "approve_url": "https://example.com/a", "reject_url": "https://example.com/r"},
+			title:     "Approval needed: Budget",
+			buttons:   2,
+		},
+		{
+			name:      "doc_updated",
+			variables: []string{"diff_url", "editor", "note", "summary", "title", "updated_at", "url"},
+			data:      map[string]interface{}{"title": "Roadmap", "url": "https://example.com/d"},
+			title:     "Roadmap was updated",
+			buttons:   1,
+		},
+		{
+			name:      "task_created",
+			variables: []string{"assignee", "creator", "description", "due", "note", "summary", "url"},
+			data:      map[string]interface{}{"summary": "Ship it", "assignee": "Ann", "url": "https://example.com/t"},
+			title:     "Task created: Ship it",
+			buttons:   1,
+		},
+	}
+	for _, tt := range tests {
+		tmpl, ok := r.Get(tt.name)
+		if !ok {
+			t.Errorf("built-in template %q is missing", tt.name)
+			continue
+		}
+		if tmpl.Source != "builtin" {
+			t.Errorf("%s: Source = %q, want builtin", tt.name, tmpl.Source)
+		}
+		if got := tmpl.Variables(); !reflect.DeepEqual(got, tt.variables) {
+			t.Errorf("%s: Variables = %v, want %v", tt.name, got, tt.variables)
+		}
+
+		card, err := tmpl.Render(tt.data)
+		if err != nil {
+			t.Errorf("%s: Render error: %v", tt.name, err)
+			continue
+		}
+		title := card["header"].(map[string]interface{})["title"].(map[string]interface{})["content"]
+		if title != tt.title {
+			t.Errorf("%s: title = %q, want %q", tt.name, title, tt.title)
+		}
+
+		buttons := 0
+		for _, e := range card["elements"].([]interface{}) {
+			if e := e.(map[string]interface{}); e["tag"] == "action" {
+				buttons = len(e["actions"].([]interface{}))
+			}
+		}
+		if buttons != tt.buttons {
+			t.Errorf("%s: %d buttons, want %d", tt.name, buttons, tt.buttons)
+		}
+	}
+
+	if got := len(r.List()); got != len(tests) {
+		t.Errorf("List has %d templates, want %d", got, len(tests))
+	}
+}
+
+func TestLoadDirOverride(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"task_created.yaml": "header:\n  title: \"New: {{summary}}\"\n",
+		"custom.json":       `{"header": {"title": "Custom"}}`,
+		"broken.yaml":       "name: broken\n",
+		"readme.txt":        "not a template",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, err := NewRegistry(dir)
+	if err == nil {
+		t.Error("a template without a title should be reported")
+	}
+
+	tmpl, ok := r.Get("task_created")
+	if !ok || tmpl.Source != filepath.Join(dir, "task_created.yaml") {
+		t.Fatalf("task_created = %#v, want it loaded from the directory", tmpl)
+	}
+	if _, ok := r.Get("custom"); !ok {
+		t.Error("custom template is missing")
+	}
+	if _, ok := r.Get("broken"); ok {
+		t.Error("broken template should not be loaded")
+	}
+	if _, ok := r.Get("doc_updated"); !ok {
+		t.Error("built-in templates that aren't overridden should stay")
+	}
+}
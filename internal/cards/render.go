@@ -0,0 +1,172 @@
+package cards
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// Variables lists the placeholders the template uses, sorted
+func (t *Template) Variables() []string {
+	seen := map[string]bool{}
+	collect := func(s string) {
+		for _, m := range placeholderPattern.FindAllStringSubmatch(s, -1) {
+			seen[m[1]] = true
+		}
+	}
+
+	collect(t.Header.Title)
+	collect(t.Text)
+	collect(t.Note)
+	for _, f := range t.Fields {
+		collect(f.Label)
+		collect(f.Value)
+	}
+	for _, b := range t.Buttons {
+		collect(b.Text)
+		collect(b.URL)
+		for _, v := range b.Value {
+			collect(v)
+		}
+	}
+
+	out := make([]string, 0, len(seen))
+	for name := range seen {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Render fills the template with data and builds the Lark card JSON.
+// Missing values render empty; only an empty title is an error.
+func (t *Template) Render(data map[string]interface{}) (map[string]interface{}, error) {
+	title := strings.TrimSpace(fill(t.Header.Title, data))
+	if title == "" {
+		return nil, errors.New("the card title rendered empty")
+	}
+
+	header := map[string]interface{}{
+		"title": map[string]interface{}{"tag": "plain_text", "content": title},
+	}
+	if t.Header.Color != "" {
+		header["template"] = t.Header.Color
+	}
+
+	elements := []interface{}{}
+
+	if text := strings.TrimSpace(fill(t.Text, data)); text != "" {
+		elements = append(elements, map[string]interface{}{
+			"tag":  "div",
+			"text": map[string]interface{}{"tag": "lark_md", "content": text},
+		})
+	}
+
+	fields := []interface{}{}
+	for _, f := range t.Fields {
+		value := strings.TrimSpace(fill(f.Value, data))
+		if value == "" {
+			continue
+		}
+		fields = append(fields, map[string]interface{}{
+			"is_short": !f.Wide,
+			"text": map[string]interface{}{
+				"tag":     "lark_md",
+				"content": "**" + fill(f.Label, data) + "**\n" + value,
+			},
+		})
+	}
+	if len(fields) > 0 {
+		elements = append(elements, map[string]interface{}{"tag": "div", "fields": fields})
+	}
+
+	actions := []interface{}{}
+	for _, b := range t.Buttons {
+		url := strings.TrimSpace(fill(b.URL, data))
+		if url == "" && len(b.Value) == 0 {
+			continue
+		}
+
+		buttonType := b.Type
+		if buttonType == "" {
+			buttonType = "default"
+		}
+		button := map[string]interface{}{
+			"tag":  "button",
+			"text": map[string]interface{}{"tag": "plain_text", "content": fill(b.Text, data)},
+			"type": buttonType,
+		}
+		if url != "" {
+			button["url"] = url
+		}
+		if len(b.Value) > 0 {
+			value := make(map[string]string, len(b.Value))
+			for k, v := range b.Value {
+				value[k] = fill(v, data)
+			}
+			button["value"] = value
+		}
+		actions = append(actions, button)
+	}
+	if len(actions) > 0 {
+		elements = append(elements, map[string]interface{}{"tag": "action", "actions": actions})
+	}
+
+	if note := strings.TrimSpace(fill(t.Note, data)); note != "" {
+		elements = append(elements, map[string]interface{}{
+			"tag":      "note",
+			"elements": []interface{}{map[string]interface{}{"tag": "plain_text", "content": note}},
+		})
+	}
+
+	return map[string]interface{}{
+		// update_multi lets the card be edited after sending
+		"config":   map[string]interface{}{"wide_screen_mode": true, "update_multi": true},
+		"header":   header,
+		"elements": elements,
+	}, nil
+}
+
+// fill replaces the placeholders in s with values from data
+func fill(s string, data map[string]interface{}) string {
+	return placeholderPattern.ReplaceAllStringFunc(s, func(m string) string {
+		key := placeholderPattern.FindStringSubmatch(m)[1]
+		return lookup(data, key)
+	})
+}
+
+// lookup resolves a dotted key in data and renders it as text
+func lookup(data map[string]interface{}, key string) string {
+	var v interface{} = data
+	for _, part := range strings.Split(key, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		v = m[part]
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, lookup(map[string]interface{}{"v": item}, "v"))
+		}
+		return strings.Join(parts, ", ")
+	}
+	out, _ := json.Marshal(v)
+	return string(out)
+}
@@ -3,14 +3,20 @@ package config
 import (
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	AppID     string
-	AppSecret string
-	Port      string
+	AppID             string
+	AppSecret         string
+	Port              string
+	DataDir           string // Where background job state is persisted
+	WikiFilesDir      string // Root for Markdown sync and export directories; request paths must stay inside it
+	WikiArchiveParent string // Wiki node that archived nodes are moved under
+	CalendarTimezone  string // IANA timezone for calendar times and Bitable dates given without an offset
+	CardTemplateDir   string // Extra card templates, overriding built-in ones of the same name
 }
 
 func LoadConfig() *Config {
@@ -22,18 +28,37 @@ func LoadConfig() *Config {
 	appID := os.Getenv("LARK_APP_ID")
 	appSecret := os.Getenv("LARK_APP_SECRET")
 	port := os.Getenv("PORT")
+	dataDir := os.Getenv("DATA_DIR")
+	wikiFilesDir := os.Getenv("WIKI_FILES_DIR")
+	wikiArchiveParent := os.Getenv("WIKI_ARCHIVE_PARENT")
+	calendarTimezone := os.Getenv("CALENDAR_TIMEZONE")
+	cardTemplateDir := os.Getenv("CARD_TEMPLATE_DIR")
 
 	if port == "" {
 		port = "8000"
 	}
+	if dataDir == "" {
+		dataDir = "./data"
+	}
+	if wikiFilesDir == "" {
+		wikiFilesDir = filepath.Join(dataDir, "wiki")
+	}
+	if calendarTimezone == "" {
+		calendarTimezone = "UTC"
+	}
 
 	if appID == "" || appSecret == "" {
 		log.Fatal("LARK_APP_ID and LARK_APP_SECRET must be set")
 	}
 
 	return &Config{
-		AppID:     appID,
-		AppSecret: appSecret,
-		Port:      port,
+		AppID:             appID,
+		AppSecret:         appSecret,
+		Port:              port,
+		DataDir:           dataDir,
+		WikiFilesDir:      wikiFilesDir,
+		WikiArchiveParent: wikiArchiveParent,
+		CalendarTimezone:  calendarTimezone,
+		CardTemplateDir:   cardTemplateDir,
 	}
 }
@@ -0,0 +1,619 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"lark-integration-skill/internal/models"
+	"lark-integration-skill/pkg/larkclient"
+
+	"github.com/gin-gonic/gin"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
+)
+
+const (
+	// maxRecordsPerBatch is the most records Lark accepts in one batch create, update or delete call
+	maxRecordsPerBatch = 500
+
+	// maxRecordSearchResults caps auto-pagination of record searches
+	maxRecordSearchResults = 10000
+)
+
+type BitableHandler struct {
+	Client   *larkclient.ClientWrapper
+	Timezone string // Default IANA timezone for date values without an offset, from config
+}
+
+func NewBitableHandler(client *larkclient.ClientWrapper, timezone string) *BitableHandler {
+	return &BitableHandler{Client: client, Timezone: timezone}
+}
+
+// ListBitableTables lists every table of a Base app
+func (h *BitableHandler) ListBitableTables(c *gin.Context) {
+	appToken := c.Param("app")
+	if appToken == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "App Token is required"})
+		return
+	}
+
+	items := []models.BitableTable{}
+	pageToken := ""
+	for {
+		builder := larkbitable.NewListAppTableReqBuilder().
+			AppToken(appToken).
+			PageSize(100)
+
+		if pageToken != "" {
+			builder.PageToken(pageToken)
+		}
+
+		resp, err := h.Client.Client.Bitable.AppTable.List(context.Background(), builder.Build())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+		if !resp.Success() {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+			return
+		}
+
+		for _, t := range resp.Data.Items {
+			items = append(items, models.BitableTable{
+				TableID:  larkcore.StringValue(t.TableId),
+				Name:     larkcore.StringValue(t.Name),
+				Revision: larkcore.IntValue(t.Revision),
+			})
+		}
+
+		if !larkcore.BoolValue(resp.Data.HasMore) || larkcore.StringValue(resp.Data.PageToken) == "" {
+			break
+		}
+		pageToken = *resp.Data.PageToken
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   models.BitableTableListResponse{Items: items},
+	})
+}
+
+// CreateBitableTable creates a table with an optional field schema
+func (h *BitableHandler) CreateBitableTable(c *gin.Context) {
+	appToken := c.Param("app")
+	if appToken == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "App Token is required"})
+		return
+	}
+
+	var req models.CreateBitableTableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	headers := make([]*larkbitable.AppTableCreateHeader, 0, len(req.Fields))
+	for _, f := range req.Fields {
+		header := larkbitable.NewAppTableCreateHeaderBuilder().
+			FieldName(f.FieldName).
+			Type(f.Type)
+
+		if len(f.Property) > 0 {
+			property, err := toFieldProperty(f.Property)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: fmt.Sprintf("Invalid property for field %q: %v", f.FieldName, err)})
+				return
+			}
+			header.Property(property)
+		}
+		headers = append(headers, header.Build())
+	}
+
+	tableBuilder := larkbitable.NewReqTableBuilder().
+		Name(req.Name)
+
+	if req.DefaultViewName != "" {
+		tableBuilder.DefaultViewName(req.DefaultViewName)
+	}
+	if len(headers) > 0 {
+		tableBuilder.Fields(headers)
+	}
+
+	input := larkbitable.NewCreateAppTableReqBuilder().
+		AppToken(appToken).
+		Body(larkbitable.NewCreateAppTableReqBodyBuilder().
+			Table(tableBuilder.Build()).
+			Build()).
+		Build()
+
+	resp, err := h.Client.Client.Bitable.AppTable.Create(context.Background(), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data: models.CreateBitableTableResponse{
+			TableID:       larkcore.StringValue(resp.Data.TableId),
+			DefaultViewID: larkcore.StringValue(resp.Data.DefaultViewId),
+			FieldIDs:      resp.Data.FieldIdList,
+		},
+	})
+}
+
+// ListBitableFields lists the fields (columns) of a table
+func (h *BitableHandler) ListBitableFields(c *gin.Context) {
+	appToken := c.Param("app")
+	tableID := c.Param("table")
+	if appToken == "" || tableID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "App Token and Table ID are required"})
+		return
+	}
+
+	fields, err := h.listFields(context.Background(), appToken, tableID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	items := make([]models.BitableField, 0, len(fields))
+	for _, f := range fields {
+		items = append(items, toBitableField(f))
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   models.BitableFieldListResponse{Items: items},
+	})
+}
+
+// SearchBitableRecords lists records with an optional filter, sort and field selection.
+// With max_results set it follows page tokens until that many records are collected.
+func (h *BitableHandler) SearchBitableRecords(c *gin.Context) {
+	appToken := c.Param("app")
+	tableID := c.Param("table")
+	if appToken == "" || tableID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "App Token and Table ID are required"})
+		return
+	}
+
+	var req models.SearchBitableRecordsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	if req.PageSize <= 0 || req.PageSize > 500 {
+		req.PageSize = 100
+	}
+	maxResults := min(req.MaxResults, maxRecordSearchResults)
+
+	loc, err := h.location(c.Query("timezone"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	mapper, err := h.fieldMapper(ctx, appToken, tableID, loc, c.Query("raw") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	data := models.BitableRecordListResponse{Items: []models.BitableRecord{}}
+	pageToken := req.PageToken
+
+	for {
+		page, err := h.searchRecords(ctx, appToken, tableID, req, pageToken)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+
+		for _, r := range page.Items {
+			record := toBitableRecord(r)
+			record.Fields = mapper.fromLarkFields(record.Fields)
+			data.Items = append(data.Items, record)
+		}
+		data.Total = larkcore.IntValue(page.Total)
+		data.HasMore = larkcore.BoolValue(page.HasMore)
+		data.PageToken = larkcore.StringValue(page.PageToken)
+
+		if len(data.Items) >= maxResults || !data.HasMore || data.PageToken == "" {
+			break
+		}
+		pageToken = data.PageToken
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   data,
+	})
+}
+
+// BatchCreateBitableRecords creates records, maxRecordsPerBatch per request
+func (h *BitableHandler) BatchCreateBitableRecords(c *gin.Context) {
+	appToken := c.Param("app")
+	tableID := c.Param("table")
+	if appToken == "" || tableID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "App Token and Table ID are required"})
+		return
+	}
+
+	var req models.BatchBitableRecordsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	loc, err := h.location(c.Query("timezone"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	mapper, err := h.fieldMapper(ctx, appToken, tableID, loc, c.Query("raw") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	records, err := mapper.toLarkRecords(ctx, req.Records)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	created, err := h.createRecords(ctx, appToken, tableID, records)
+	respondBitableBatch(c, "Create", mapper, created, err)
+}
+
+// BatchUpdateBitableRecords updates the given fields of existing records, maxRecordsPerBatch per request
+func (h *BitableHandler) BatchUpdateBitableRecords(c *gin.Context) {
+	appToken := c.Param("app")
+	tableID := c.Param("table")
+	if appToken == "" || tableID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "App Token and Table ID are required"})
+		return
+	}
+
+	var req models.BatchBitableRecordsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	for _, r := range req.Records {
+		if r.RecordID == "" {
+			c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Every record needs a record_id"})
+			return
+		}
+	}
+
+	loc, err := h.location(c.Query("timezone"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	mapper, err := h.fieldMapper(ctx, appToken, tableID, loc, c.Query("raw") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	records, err := mapper.toLarkRecords(ctx, req.Records)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	updated, err := h.updateRecords(ctx, appToken, tableID, records)
+	respondBitableBatch(c, "Update", mapper, updated, err)
+}
+
+// BatchDeleteBitableRecords deletes records by ID, maxRecordsPerBatch per request
+func (h *BitableHandler) BatchDeleteBitableRecords(c *gin.Context) {
+	appToken := c.Param("app")
+	tableID := c.Param("table")
+	if appToken == "" || tableID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "App Token and Table ID are required"})
+		return
+	}
+
+	var req models.BatchDeleteBitableRecordsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	deleted := []string{}
+	for start := 0; start < len(req.RecordIDs); start += maxRecordsPerBatch {
+		batch := req.RecordIDs[start:min(start+maxRecordsPerBatch, len(req.RecordIDs))]
+
+		input := larkbitable.NewBatchDeleteAppTableRecordReqBuilder().
+			AppToken(appToken).
+			TableId(tableID).
+			Body(larkbitable.NewBatchDeleteAppTableRecordReqBodyBuilder().
+				Records(batch).
+				Build()).
+			Build()
+
+		resp, err := h.Client.Client.Bitable.AppTableRecord.BatchDelete(context.Background(), input)
+		if err == nil && !resp.Success() {
+			err = fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Status:  "error",
+				Message: fmt.Sprintf("Delete stopped after %d records: %v", len(deleted), err),
+				Data:    models.BitableBatchDeleteResponse{Deleted: deleted},
+			})
+			return
+		}
+
+		for _, r := range resp.Data.Records {
+			if larkcore.BoolValue(r.Deleted) {
+				deleted = append(deleted, larkcore.StringValue(r.RecordId))
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   models.BitableBatchDeleteResponse{Deleted: deleted},
+	})
+}
+
+func respondBitableBatch(c *gin.Context, verb string, mapper *bitableFieldMapper, records []*larkbitable.AppTableRecord, err error) {
+	items := make([]models.BitableRecord, 0, len(records))
+	for _, r := range records {
+		record := toBitableRecord(r)
+		record.Fields = mapper.fromLarkFields(record.Fields)
+		items = append(items, record)
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Status:  "error",
+			Message: fmt.Sprintf("%s stopped after %d records: %v", verb, len(items), err),
+			Data:    models.BitableBatchResponse{Records: items},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   models.BitableBatchResponse{Records: items},
+	})
+}
+
+// fieldMapper loads the table's field schema for value conversion, or returns nil when raw Lark values are wanted
+func (h *BitableHandler) fieldMapper(ctx context.Context, appToken, tableID string, loc *time.Location, raw bool) (*bitableFieldMapper, error) {
+	if raw {
+		return nil, nil
+	}
+	return h.newFieldMapper(ctx, appToken, tableID, loc)
+}
+
+// location loads tz, or the configured default when tz is empty, for reading and writing date values
+func (h *BitableHandler) location(tz string) (*time.Location, error) {
+	if tz == "" {
+		tz = h.Timezone
+	}
+	if tz == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q", tz)
+	}
+	return loc, nil
+}
+
+// listFields pages through a table's fields
+func (h *BitableHandler) listFields(ctx context.Context, appToken, tableID string) ([]*larkbitable.AppTableFieldForList, error) {
+	var fields []*larkbitable.AppTableFieldForList
+	pageToken := ""
+
+	for {
+		builder := larkbitable.NewListAppTableFieldReqBuilder().
+			AppToken(appToken).
+			TableId(tableID).
+			PageSize(100)
+
+		if pageToken != "" {
+			builder.PageToken(pageToken)
+		}
+
+		resp, err := h.Client.Client.Bitable.AppTableField.List(ctx, builder.Build())
+		if err != nil {
+			return nil, err
+		}
+		if !resp.Success() {
+			return nil, fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+		}
+
+		fields = append(fields, resp.Data.Items...)
+
+		if !larkcore.BoolValue(resp.Data.HasMore) || larkcore.StringValue(resp.Data.PageToken) == "" {
+			return fields, nil
+		}
+		pageToken = *resp.Data.PageToken
+	}
+}
+
+// searchRecords fetches one page of a record search
+func (h *BitableHandler) searchRecords(ctx context.Context, appToken, tableID string, req models.SearchBitableRecordsRequest, pageToken string) (*larkbitable.SearchAppTableRecordRespData, error) {
+	bodyBuilder := larkbitable.NewSearchAppTableRecordReqBodyBuilder().
+		AutomaticFields(true)
+
+	if req.ViewID != "" {
+		bodyBuilder.ViewId(req.ViewID)
+	}
+	if len(req.FieldNames) > 0 {
+		bodyBuilder.FieldNames(req.FieldNames)
+	}
+	if len(req.Sort) > 0 {
+		sorts := make([]*larkbitable.Sort, 0, len(req.Sort))
+		for _, s := range req.Sort {
+			sorts = append(sorts, larkbitable.NewSortBuilder().FieldName(s.FieldName).Desc(s.Desc).Build())
+		}
+		bodyBuilder.Sort(sorts)
+	}
+	if req.Filter != nil && len(req.Filter.Conditions) > 0 {
+		conjunction := req.Filter.Conjunction
+		if conjunction == "" {
+			conjunction = "and"
+		}
+		conditions := make([]*larkbitable.Condition, 0, len(req.Filter.Conditions))
+		for _, cond := range req.Filter.Conditions {
+			conditions = append(conditions, larkbitable.NewConditionBuilder().
+				FieldName(cond.FieldName).
+				Operator(cond.Operator).
+				Value(cond.Value).
+				Build())
+		}
+		bodyBuilder.Filter(larkbitable.NewFilterInfoBuilder().
+			Conjunction(conjunction).
+			Conditions(conditions).
+			Build())
+	}
+
+	builder := larkbitable.NewSearchAppTableRecordReqBuilder().
+		AppToken(appToken).
+		TableId(tableID).
+		PageSize(req.PageSize).
+		Body(bodyBuilder.Build())
+
+	if pageToken != "" {
+		builder.PageToken(pageToken)
+	}
+
+	resp, err := h.Client.Client.Bitable.AppTableRecord.Search(ctx, builder.Build())
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success() {
+		return nil, fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+	}
+	return resp.Data, nil
+}
+
+// createRecords creates records in batches. On error it returns the records created so far.
+func (h *BitableHandler) createRecords(ctx context.Context, appToken, tableID string, records []models.BitableRecord) ([]*larkbitable.AppTableRecord, error) {
+	var created []*larkbitable.AppTableRecord
+
+	for start := 0; start < len(records); start += maxRecordsPerBatch {
+		batch := records[start:min(start+maxRecordsPerBatch, len(records))]
+
+		input := larkbitable.NewBatchCreateAppTableRecordReqBuilder().
+			AppToken(appToken).
+			TableId(tableID).
+			Body(larkbitable.NewBatchCreateAppTableRecordReqBodyBuilder().
+				Records(toAppTableRecords(batch)).
+				Build()).
+			Build()
+
+		resp, err := h.Client.Client.Bitable.AppTableRecord.BatchCreate(ctx, input)
+		if err != nil {
+			return created, err
+		}
+		if !resp.Success() {
+			return created, fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+		}
+		created = append(created, resp.Data.Records...)
+	}
+
+	return created, nil
+}
+
+// updateRecords updates records in batches. On error it returns the records updated so far.
+func (h *BitableHandler) updateRecords(ctx context.Context, appToken, tableID string, records []models.BitableRecord) ([]*larkbitable.AppTableRecord, error) {
+	var updated []*larkbitable.AppTableRecord
+
+	for start := 0; start < len(records); start += maxRecordsPerBatch {
+		batch := records[start:min(start+maxRecordsPerBatch, len(records))]
+
+		input := larkbitable.NewBatchUpdateAppTableRecordReqBuilder().
+			AppToken(appToken).
+			TableId(tableID).
+			Body(larkbitable.NewBatchUpdateAppTableRecordReqBodyBuilder().
+				Records(toAppTableRecords(batch)).
+				Build()).
+			Build()
+
+		resp, err := h.Client.Client.Bitable.AppTableRecord.BatchUpdate(ctx, input)
+		if err != nil {
+			return updated, err
+		}
+		if !resp.Success() {
+			return updated, fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+		}
+		updated = append(updated, resp.Data.Records...)
+	}
+
+	return updated, nil
+}
+
+func toAppTableRecords(records []models.BitableRecord) []*larkbitable.AppTableRecord {
+	out := make([]*larkbitable.AppTableRecord, 0, len(records))
+	for _, r := range records {
+		builder := larkbitable.NewAppTableRecordBuilder().
+			Fields(r.Fields)
+
+		if r.RecordID != "" {
+			builder.RecordId(r.RecordID)
+		}
+		out = append(out, builder.Build())
+	}
+	return out
+}
+
+// toFieldProperty converts a free-form property object into the SDK type via its JSON shape
+func toFieldProperty(property map[string]interface{}) (*larkbitable.AppTableFieldProperty, error) {
+	data, err := json.Marshal(property)
+	if err != nil {
+		return nil, err
+	}
+	var out larkbitable.AppTableFieldProperty
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func toBitableField(f *larkbitable.AppTableFieldForList) models.BitableField {
+	field := models.BitableField{
+		FieldID:   larkcore.StringValue(f.FieldId),
+		FieldName: larkcore.StringValue(f.FieldName),
+		Type:      larkcore.IntValue(f.Type),
+		UiType:    larkcore.StringValue(f.UiType),
+		IsPrimary: larkcore.BoolValue(f.IsPrimary),
+	}
+	if f.Property != nil {
+		field.Property = f.Property
+	}
+	return field
+}
+
+func toBitableRecord(r *larkbitable.AppTableRecord) models.BitableRecord {
+	fields := r.Fields
+	if fields == nil {
+		fields = map[string]interface{}{}
+	}
+	return models.BitableRecord{
+		RecordID:         larkcore.StringValue(r.RecordId),
+		Fields:           fields,
+		CreatedTime:      larkcore.Int64Value(r.CreatedTime),
+		LastModifiedTime: larkcore.Int64Value(r.LastModifiedTime),
+	}
+}
@@ -0,0 +1,394 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"lark-integration-skill/internal/models"
+
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
+)
+
+// Bitable field types, as reported in a field's type
+const (
+	bitableText         = 1
+	bitableNumber       = 2
+	bitableSingleSelect = 3
+	bitableMultiSelect  = 4
+	bitableDateTime     = 5
+	bitableCheckbox     = 7
+	bitableUser         = 11
+	bitablePhone        = 13
+	bitableURL          = 15
+	bitableAttachment   = 17
+	bitableSingleLink   = 18
+	bitableLookup       = 19
+	bitableFormula      = 20
+	bitableDuplexLink   = 21
+	bitableLocation     = 22
+	bitableGroupChat    = 23
+	bitableCreatedTime  = 1001
+	bitableModifiedTime = 1002
+	bitableCreatedUser  = 1003
+	bitableModifiedUser = 1004
+	bitableAutoNumber   = 1005
+)
+
+// bitableDateLayouts are the date formats accepted for date fields besides epoch milliseconds, tried in order.
+// Layouts without a zone are read in the request's timezone, default the configured CALENDAR_TIMEZONE.
+var bitableDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// bitableFieldMapper converts record fields between friendly JSON and the value shapes Lark expects for each field type.
+// Values already in Lark's shape pass through unchanged. A nil mapper leaves values alone.
+type bitableFieldMapper struct {
+	h      *BitableHandler
+	fields map[string]*larkbitable.AppTableFieldForList // by field name and by field ID
+	loc    *time.Location                               // Zone for dates given without an offset, and for dates read back
+}
+
+// newFieldMapper loads the table's field schema
+func (h *BitableHandler) newFieldMapper(ctx context.Context, appToken, tableID string, loc *time.Location) (*bitableFieldMapper, error) {
+	fields, err := h.listFields(ctx, appToken, tableID)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &bitableFieldMapper{h: h, fields: make(map[string]*larkbitable.AppTableFieldForList, len(fields)*2), loc: loc}
+	for _, f := range fields {
+		m.fields[larkcore.StringValue(f.FieldName)] = f
+		m.fields[larkcore.StringValue(f.FieldId)] = f
+	}
+	return m, nil
+}
+
+// toLarkRecords converts the fields of records to Lark's value shapes, keyed by field name.
+// Person fields given as emails are resolved to open IDs with one lookup for all records.
+func (m *bitableFieldMapper) toLarkRecords(ctx context.Context, records []models.BitableRecord) ([]models.BitableRecord, error) {
+	if m == nil {
+		return records, nil
+	}
+
+	emails, err := m.resolveEmails(ctx, records)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]models.BitableRecord, len(records))
+	for i, r := range records {
+		fields := make(map[string]interface{}, len(r.Fields))
+		for key, value := range r.Fields {
+			field, ok := m.fields[key]
+			if !ok {
+				return nil, fmt.Errorf("record %d: unknown field %q", i, key)
+			}
+			name := larkcore.StringValue(field.FieldName)
+
+			converted, err := toLarkFieldValue(field, value, emails, m.loc)
+			if err != nil {
+				return nil, fmt.Errorf("record %d: field %q: %v", i, name, err)
+			}
+			fields[name] = converted
+		}
+		out[i] = r
+		out[i].Fields = fields
+	}
+	return out, nil
+}
+
+// fromLarkFields converts a record's fields as returned by Lark to friendly values
+func (m *bitableFieldMapper) fromLarkFields(fields map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return fields
+	}
+
+	out := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		if field, ok := m.fields[key]; ok {
+			out[key] = fromLarkFieldValue(larkcore.IntValue(field.Type), value, m.loc)
+		} else {
+			out[key] = value
+		}
+	}
+	return out
+}
+
+// resolveEmails looks up the open IDs of every email given in a person field
+func (m *bitableFieldMapper) resolveEmails(ctx context.Context, records []models.BitableRecord) (map[string]string, error) {
+	seen := map[string]bool{}
+	var emails []string
+	for _, r := range records {
+		for key, value := range r.Fields {
+			field, ok := m.fields[key]
+			if !ok || larkcore.IntValue(field.Type) != bitableUser {
+				continue
+			}
+			for _, item := range asList(value) {
+				if s, ok := item.(string); ok && strings.Contains(s, "@") && !seen[s] {
+					seen[s] = true
+					emails = append(emails, s)
+				}
+			}
+		}
+	}
+
+	openIDs, err := lookupOpenIDs(ctx, m.h.Client, emails)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, email := range emails {
+		if _, ok := openIDs[strings.ToLower(email)]; !ok {
+			return nil, fmt.Errorf("no user found for email %q", email)
+		}
+	}
+	return openIDs, nil
+}
+
+// toLarkFieldValue converts one friendly value to the shape Lark expects for the field. null clears the field.
+func toLarkFieldValue(field *larkbitable.AppTableFieldForList, value interface{}, emails map[string]string, loc *time.Location) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch larkcore.IntValue(field.Type) {
+	case bitableText, bitablePhone:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case float64, bool:
+			return cellString(v), nil
+		}
+
+	case bitableNumber:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case string:
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a number", v)
+			}
+			return f, nil
+		}
+
+	case bitableSingleSelect:
+		if s, ok := value.(string); ok {
+			return optionName(field, s), nil
+		}
+
+	case bitableMultiSelect:
+		names := []string{}
+		for _, item := range asList(value) {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("options must be strings")
+			}
+			names = append(names, optionName(field, s))
+		}
+		return names, nil
+
+	case bitableDateTime:
+		switch v := value.(type) {
+		case float64:
+			return int64(v), nil
+		case string:
+			return parseBitableDate(v, loc)
+		}
+
+	case bitableCheckbox:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a boolean", v)
+			}
+			return b, nil
+		}
+
+	case bitableUser, bitableGroupChat:
+		people := []interface{}{}
+		for _, item := range asList(value) {
+			switch v := item.(type) {
+			case string:
+				if id, ok := emails[strings.ToLower(v)]; ok {
+					v = id
+				}
+				people = append(people, map[string]interface{}{"id": v})
+			case map[string]interface{}:
+				people = append(people, v)
+			default:
+				return nil, fmt.Errorf("expected an email, an ID or an object with id")
+			}
+		}
+		return people, nil
+
+	case bitableURL:
+		switch v := value.(type) {
+		case string:
+			return map[string]interface{}{"link": v, "text": v}, nil
+		case map[string]interface{}:
+			return v, nil
+		}
+
+	case bitableAttachment:
+		files := []interface{}{}
+		for _, item := range asList(value) {
+			switch v := item.(type) {
+			case string:
+				files = append(files, map[string]interface{}{"file_token": v})
+			case map[string]interface{}:
+				files = append(files, v)
+			default:
+				return nil, fmt.Errorf("expected a file token or an object with file_token")
+			}
+		}
+		return files, nil
+
+	case bitableSingleLink, bitableDuplexLink:
+		ids := []string{}
+		for _, item := range asList(value) {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("linked records must be record IDs")
+			}
+			ids = append(ids, s)
+		}
+		return ids, nil
+
+	case bitableLookup, bitableFormula, bitableCreatedTime, bitableModifiedTime, bitableCreatedUser, bitableModifiedUser, bitableAutoNumber:
+		return nil, fmt.Errorf("field is read-only")
+
+	default:
+		// Types without a friendly form, such as location, are sent as given
+		return value, nil
+	}
+
+	return nil, fmt.Errorf("unexpected value %v for field type %d", value, larkcore.IntValue(field.Type))
+}
+
+// fromLarkFieldValue simplifies a value read from Lark: text segments become a string, dates become RFC 3339,
+// people become emails (or IDs when the email isn't visible), URLs become the link and links become record IDs
+func fromLarkFieldValue(fieldType int, value interface{}, loc *time.Location) interface{} {
+	switch fieldType {
+	case bitableText, bitablePhone, bitableAutoNumber:
+		if _, ok := value.([]interface{}); ok {
+			return cellString(value)
+		}
+
+	case bitableDateTime, bitableCreatedTime, bitableModifiedTime:
+		if ms, ok := value.(float64); ok {
+			return time.UnixMilli(int64(ms)).In(loc).Format(time.RFC3339)
+		}
+
+	case bitableUser, bitableCreatedUser, bitableModifiedUser:
+		if list, ok := value.([]interface{}); ok {
+			people := make([]interface{}, 0, len(list))
+			for _, item := range list {
+				people = append(people, personValue(item))
+			}
+			return people
+		}
+		return personValue(value)
+
+	case bitableURL:
+		if m, ok := value.(map[string]interface{}); ok {
+			if link, ok := m["link"].(string); ok {
+				return link
+			}
+		}
+
+	case bitableSingleLink, bitableDuplexLink:
+		return linkedRecordIDs(value)
+	}
+
+	return value
+}
+
+// personValue returns a person's email, or their ID when the email isn't visible to the app
+func personValue(v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	if email, ok := m["email"].(string); ok && email != "" {
+		return email
+	}
+	if id, ok := m["id"].(string); ok {
+		return id
+	}
+	return v
+}
+
+// linkedRecordIDs reads the record IDs of a link field, which come either as {"link_record_ids": [...]}
+// or as a list of {"record_ids": [...]} depending on the API
+func linkedRecordIDs(value interface{}) interface{} {
+	ids := []interface{}{}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		list, ok := v["link_record_ids"].([]interface{})
+		if !ok {
+			return value
+		}
+		ids = append(ids, list...)
+	case []interface{}:
+		for _, item := range v {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				return value
+			}
+			if list, ok := m["record_ids"].([]interface{}); ok {
+				ids = append(ids, list...)
+			}
+		}
+	default:
+		return value
+	}
+	return ids
+}
+
+// optionName returns the existing option matching s case-insensitively, or s itself, which Lark adds as a new option
+func optionName(field *larkbitable.AppTableFieldForList, s string) string {
+	if field.Property == nil {
+		return s
+	}
+	for _, opt := range field.Property.Options {
+		if name := larkcore.StringValue(opt.Name); strings.EqualFold(name, s) {
+			return name
+		}
+	}
+	return s
+}
+
+// parseBitableDate parses an ISO date or epoch milliseconds into epoch milliseconds, reading dates without an offset in loc
+func parseBitableDate(s string, loc *time.Location) (int64, error) {
+	s = strings.TrimSpace(s)
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return ms, nil
+	}
+	for _, layout := range bitableDateLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t.UnixMilli(), nil
+		}
+	}
+	return 0, fmt.Errorf("%q is not a date; use ISO 8601 such as 2024-05-01 or 2024-05-01T09:30:00+08:00", s)
+}
+
+// asList wraps a single value in a list so one-or-many fields accept both
+func asList(v interface{}) []interface{} {
+	if list, ok := v.([]interface{}); ok {
+		return list
+	}
+	return []interface{}{v}
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
+)
+
+// testField builds a field of the given type with select options
+func testField(fieldType int, options ...string) *larkbitable.AppTableFieldForList {
+	field := &larkbitable.AppTableFieldForList{
+		FieldName: larkcore.StringPtr("Field"),
+		Type:      larkcore.IntPtr(fieldType),
+	}
+	if len(options) > 0 {
+		field.Property = &larkbitable.AppTableFieldProperty{}
+		for _, o := range options {
+			field.Property.Options = append(field.Property.Options, &larkbitable.AppTableFieldPropertyOption{Name: larkcore.StringPtr(o)})
+		}
+	}
+	return field
+}
+
+func TestToLarkFieldValue(t *testing.T) {
+	emails := map[string]string{"ann@example.com": "ou_ann"}
+	date := time.Date(2024, 5, 1, 9, 30, 0, 0, time.FixedZone("", 8*3600))
+
+	tests := []struct {
+		name  string
+		field *larkbitable.AppTableFieldForList
+		value interface{}
+		want  interface{}
+	}{
+		{"null clears", testField(bitableText), nil, nil},
+		{"text", testField(bitableText), "hi", "hi"},
+		{"number as text", testField(bitableText), 12.0, "12"},
+		{"number", testField(bitableNumber), 3.5, 3.5},
+		{"number from string", testField(bitableNumber), " 42 ", 42.0},
+		{"single select matches option case", testField(bitableSingleSelect, "Done", "Todo"), "done", "Done"},
+		{"single select new option", testField(bitableSingleSelect, "Done"), "Blocked", "Blocked"},
+		{"multi select from one", testField(bitableMultiSelect, "A", "B"), "b", []string{"B"}},
+		{"multi select", testField(bitableMultiSelect, "A", "B"), []interface{}{"a", "C"}, []string{"A", "C"}},
+		{"date from millis", testField(bitableDateTime), 1714527000000.0, int64(1714527000000)},
+		{"date from RFC 3339", testField(bitableDateTime), date.Format(time.RFC3339), date.UnixMilli()},
+		{"checkbox", testField(bitableCheckbox), true, true},
+		{"checkbox from string", testField(bitableCheckbox), "false", false},
+		{"person by email", testField(bitableUser), "Ann@Example.com", []interface{}{map[string]interface{}{"id": "ou_ann"}}},
+		{"person by id", testField(bitableUser), []interface{}{"ou_bob"}, []interface{}{map[string]interface{}{"id": "ou_bob"}}},
+		{"url", testField(bitableURL), "https://example.com", map[string]interface{}{"link": "https://example.com", "text": "https://example.com"}},
+		{"attachment", testField(bitableAttachment), "tok", []interface{}{map[string]interface{}{"file_token": "tok"}}},
+		{"link", testField(bitableDuplexLink), []interface{}{"rec1", "rec2"}, []string{"rec1", "rec2"}},
+		{"location passes through", testField(bitableLocation), map[string]interface{}{"location": "1,2"}, map[string]interface{}{"location": "1,2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := toLarkFieldValue(tt.field, tt.value, emails, time.UTC)
+			if err != nil {
+				t.Fatalf("toLarkFieldValue(%#v) error: %v", tt.value, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("toLarkFieldValue(%#v) = %#v, want %#v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToLarkFieldValueErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		field *larkbitable.AppTableFieldForList
+		value interface{}
+	}{
+		{"number", testField(bitableNumber), "many"},
+		{"checkbox", testField(bitableCheckbox), "maybe"},
+		{"date", testField(bitableDateTime), "next week"},
+		{"multi select", testField(bitableMultiSelect), []interface{}{1.0}},
+		{"link", testField(bitableSingleLink), []interface{}{true}},
+		{"read-only", testField(bitableFormula), "x"},
+		{"wrong shape", testField(bitableText), []interface{}{"a"}},
+	}
+	for _, tt := range tests {
+		if got, err := toLarkFieldValue(tt.field, tt.value, nil, time.UTC); err == nil {
+			t.Errorf("%s: toLarkFieldValue(%#v) = %#v, want an error", tt.name, tt.value, got)
+		}
+	}
+}
+
+func TestFromLarkFieldValue(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*3600)
+	ms := float64(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC).UnixMilli())
+
+	tests := []struct {
+		name      string
+		fieldType int
+		value     interface{}
+		want      interface{}
+	}{
+		{"text segments", bitableText, []interface{}{map[string]interface{}{"type": "text", "text": "a"}, map[string]interface{}{"type": "url", "text": "b"}}, "ab"},
+		{"plain text", bitableText, "a", "a"},
+		{"date", bitableDateTime, ms, "2024-05-01T09:00:00+09:00"},
+		{"person with email", bitableUser, []interface{}{map[string]interface{}{"id": "ou_1", "email": "a@example.com"}}, []interface{}{"a@example.com"}},
+		{"person without email", bitableCreatedUser, map[string]interface{}{"id": "ou_1"}, "ou_1"},
+		{"url", bitableURL, map[string]interface{}{"link": "https://example.com", "text": "site"}, "https://example.com"},
+		{"link from search", bitableDuplexLink, map[string]interface{}{"link_record_ids": []interface{}{"rec1"}}, []interface{}{"rec1"}},
+		{"link from list", bitableSingleLink, []interface{}{map[string]interface{}{"record_ids": []interface{}{"rec1", "rec2"}}}, []interface{}{"rec1", "rec2"}},
+		{"number", bitableNumber, 3.0, 3.0},
+	}
+	for _, tt := range tests {
+		if got := fromLarkFieldValue(tt.fieldType, tt.value, tokyo); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: fromLarkFieldValue(%#v) = %#v, want %#v", tt.name, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseBitableDate(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*3600)
+	local := func(y int, m time.Month, d, h, minute int) int64 {
+		return time.Date(y, m, d, h, minute, 0, 0, tokyo).UnixMilli()
+	}
+
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"1714527000000", 1714527000000},
+		{"2024-05-01T09:30:00Z", time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC).UnixMilli()},
+		{"2024-05-01T09:30:00", local(2024, 5, 1, 9, 30)},
+		{"2024-05-01 09:30", local(2024, 5, 1, 9, 30)},
+		{"2024-05-01", local(2024, 5, 1, 0, 0)},
+	}
+	for _, tt := range tests {
+		got, err := parseBitableDate(tt.in, tokyo)
+		if err != nil || got != tt.want {
+			t.Errorf("parseBitableDate(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+
+	if _, err := parseBitableDate("May 1st", tokyo); err == nil {
+		t.Error("parseBitableDate(\"May 1st\") should fail")
+	}
+}
+
+func TestBitableLocation(t *testing.T) {
+	h := &BitableHandler{Timezone: "Asia/Tokyo"}
+	tests := []struct {
+		tz      string
+		want    string
+		wantErr bool
+	}{
+		{tz: "", want: "Asia/Tokyo"},
+		{tz: "Europe/Berlin", want: "Europe/Berlin"},
+		{tz: "Mars/Base", wantErr: true},
+	}
+	for _, tt := range tests {
+		loc, err := h.location(tt.tz)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("location(%q) = %v, want an error", tt.tz, loc)
+			}
+			continue
+		}
+		if err != nil || loc.String() != tt.want {
+			t.Errorf("location(%q) = %v, %v, want %s", tt.tz, loc, err, tt.want)
+		}
+	}
+
+	if loc, err := (&BitableHandler{}).location(""); err != nil || loc != time.UTC {
+		t.Errorf("location without a configured timezone = %v, %v, want UTC", loc, err)
+	}
+}
@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"lark-integration-skill/internal/models"
+
+	"github.com/gin-gonic/gin"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+)
+
+// maxKeysPerSearch is how many key values are OR-ed into one record search; Lark allows 50 conditions per filter
+const maxKeysPerSearch = 50
+
+// UpsertBitableRecords updates the records whose key field matches and creates the rest.
+// Records whose given fields already hold the same values are left alone.
+func (h *BitableHandler) UpsertBitableRecords(c *gin.Context) {
+	appToken := c.Param("app")
+	tableID := c.Param("table")
+	if appToken == "" || tableID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "App Token and Table ID are required"})
+		return
+	}
+
+	var req models.UpsertBitableRecordsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	loc, err := h.location(c.Query("timezone"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+
+	// The schema is needed to compare values even when they are sent raw
+	schema, err := h.newFieldMapper(ctx, appToken, tableID, loc)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	mapper := schema
+	if c.Query("raw") == "true" {
+		mapper = nil
+	}
+
+	keyField, ok := schema.fields[req.KeyField]
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: fmt.Sprintf("Unknown key field %q", req.KeyField)})
+		return
+	}
+	switch larkcore.IntValue(keyField.Type) {
+	case bitableText, bitableNumber, bitableSingleSelect, bitablePhone:
+	default:
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Key field must be a text, number, single select or phone field"})
+		return
+	}
+	keyName := larkcore.StringValue(keyField.FieldName)
+
+	records, err := mapper.toLarkRecords(ctx, req.Records)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	keys := make([]string, len(records))
+	seen := map[string]bool{}
+	for i, r := range records {
+		key := fieldKey(fieldByName(r.Fields, keyName, req.KeyField))
+		if key == "" {
+			c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: fmt.Sprintf("record %d has no value for key field %q", i, keyName)})
+			return
+		}
+		if seen[key] {
+			c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: fmt.Sprintf("record %d repeats key %q", i, key)})
+			return
+		}
+		seen[key] = true
+		keys[i] = key
+	}
+
+	existing, err := h.findRecordsByKey(ctx, appToken, tableID, keyName, keys)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	data := models.UpsertBitableRecordsResponse{Results: make([]models.BitableUpsertResult, len(records))}
+	var toCreate, toUpdate []models.BitableRecord
+	var createIdx, updateIdx []int
+
+	for i, r := range records {
+		data.Results[i] = models.BitableUpsertResult{Key: keys[i], Action: SyncFailed}
+
+		current, ok := existing[keys[i]]
+		if !ok {
+			toCreate = append(toCreate, r)
+			createIdx = append(createIdx, i)
+			continue
+		}
+
+		data.Results[i].RecordID = current.RecordID
+		if schema.unchanged(r.Fields, current.Fields) {
+			data.Results[i].Action = SyncUnchanged
+			data.Unchanged++
+			continue
+		}
+		r.RecordID = current.RecordID
+		toUpdate = append(toUpdate, r)
+		updateIdx = append(updateIdx, i)
+	}
+
+	updated, err := h.updateRecords(ctx, appToken, tableID, toUpdate)
+	for j := range updated {
+		data.Results[updateIdx[j]].Action = SyncUpdated
+		data.Updated++
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Status:  "error",
+			Message: fmt.Sprintf("Upsert stopped after updating %d records: %v", len(updated), err),
+			Data:    data,
+		})
+		return
+	}
+
+	// Batch create returns the records in request order
+	created, err := h.createRecords(ctx, appToken, tableID, toCreate)
+	for j, r := range created {
+		data.Results[createIdx[j]].RecordID = larkcore.StringValue(r.RecordId)
+		data.Results[createIdx[j]].Action = SyncCreated
+		data.Created++
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Status:  "error",
+			Message: fmt.Sprintf("Upsert stopped after creating %d records: %v", len(created), err),
+			Data:    data,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   data,
+	})
+}
+
+// findRecordsByKey searches for the records whose key field equals one of keys, maxKeysPerSearch keys per search.
+// When several records share a key, the first one found wins.
+func (h *BitableHandler) findRecordsByKey(ctx context.Context, appToken, tableID, keyName string, keys []string) (map[string]models.BitableRecord, error) {
+	found := make(map[string]models.BitableRecord, len(keys))
+
+	for start := 0; start < len(keys); start += maxKeysPerSearch {
+		batch := keys[start:min(start+maxKeysPerSearch, len(keys))]
+
+		conditions := make([]models.BitableCondition, 0, len(batch))
+		wanted := make(map[string]bool, len(batch))
+		for _, key := range batch {
+			conditions = append(conditions, models.BitableCondition{FieldName: keyName, Operator: "is", Value: []string{key}})
+			wanted[key] = true
+		}
+		req := models.SearchBitableRecordsRequest{
+			Filter:   &models.BitableFilter{Conjunction: "or", Conditions: conditions},
+			PageSize: 500,
+		}
+
+		pageToken := ""
+		for {
+			page, err := h.searchRecords(ctx, appToken, tableID, req, pageToken)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, r := range page.Items {
+				record := toBitableRecord(r)
+				// The filter may match loosely, e.g. ignoring case, so keep exact matches only
+				key := fieldKey(record.Fields[keyName])
+				if _, dup := found[key]; wanted[key] && !dup {
+					found[key] = record
+				}
+			}
+
+			if !larkcore.BoolValue(page.HasMore) || larkcore.StringValue(page.PageToken) == "" {
+				break
+			}
+			pageToken = *page.PageToken
+		}
+	}
+
+	return found, nil
+}
+
+// unchanged reports whether every field in want, given in Lark's write shape, already holds that value in have,
+// as read from Lark
+func (m *bitableFieldMapper) unchanged(want, have map[string]interface{}) bool {
+	for key, value := range want {
+		field, ok := m.fields[key]
+		if !ok {
+			return false
+		}
+		if !sameFieldValue(larkcore.IntValue(field.Type), value, have[larkcore.StringValue(field.FieldName)]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameFieldValue compares a value in write shape with one in read shape. Multi-value fields compare as sets.
+func sameFieldValue(fieldType int, want, have interface{}) bool {
+	// An unchecked checkbox is read back as missing
+	if fieldType == bitableCheckbox {
+		w, _ := want.(bool)
+		h, _ := have.(bool)
+		return w == h
+	}
+	if isEmptyValue(want) || isEmptyValue(have) {
+		return isEmptyValue(want) && isEmptyValue(have)
+	}
+
+	switch fieldType {
+	case bitableText, bitablePhone, bitableNumber, bitableSingleSelect, bitableDateTime:
+		return fieldKey(want) == fieldKey(have)
+
+	case bitableMultiSelect, bitableSingleLink, bitableDuplexLink:
+		return sameStrings(stringList(want), stringList(linkedRecordIDs(have)))
+
+	case bitableUser, bitableGroupChat:
+		return sameStrings(objectKeys(want, "id"), objectKeys(have, "id"))
+
+	case bitableAttachment:
+		return sameStrings(objectKeys(want, "file_token"), objectKeys(have, "file_token"))
+
+	case bitableURL:
+		return fromLarkFieldValue(bitableURL, want, time.UTC) == fromLarkFieldValue(bitableURL, have, time.UTC)
+	}
+
+	return jsonEqual(want, have)
+}
+
+// fieldKey renders a key or scalar field value as a string. Numbers compare by value, e.g. 10 and 10.0 match.
+func fieldKey(v interface{}) string {
+	switch v := v.(type) {
+	case int64:
+		return cellString(float64(v))
+	case []string:
+		return strings.Join(v, "")
+	}
+	return strings.TrimSpace(cellString(v))
+}
+
+// fieldByName returns a field's value whether the record keys it by name or by ID
+func fieldByName(fields map[string]interface{}, name, alias string) interface{} {
+	if v, ok := fields[name]; ok {
+		return v
+	}
+	return fields[alias]
+}
+
+func isEmptyValue(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []string:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+func stringList(v interface{}) []string {
+	switch v := v.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			out = append(out, cellString(item))
+		}
+		return out
+	}
+	return []string{cellString(v)}
+}
+
+// objectKeys collects the given key from a list of objects
+func objectKeys(v interface{}, key string) []string {
+	var out []string
+	for _, item := range asList(v) {
+		if m, ok := item.(map[string]interface{}); ok {
+			out = append(out, cellString(m[key]))
+		}
+	}
+	return out
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return reflect.DeepEqual(a, b)
+}
+
+// jsonEqual compares two values by their JSON encoding
+func jsonEqual(a, b interface{}) bool {
+	x, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	y, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	var u, v interface{}
+	json.Unmarshal(x, &u)
+	json.Unmarshal(y, &v)
+	return reflect.DeepEqual(u, v)
+}
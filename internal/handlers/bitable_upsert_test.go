@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"testing"
+
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
+)
+
+func TestSameFieldValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		fieldType int
+		want      interface{}
+		have      interface{}
+		same      bool
+	}{
+		{"text", bitableText, "a", []interface{}{map[string]interface{}{"text": "a"}}, true},
+		{"text differs", bitableText, "a", "b", false},
+		{"number int and float", bitableNumber, 10.0, 10.0, true},
+		{"date millis", bitableDateTime, int64(1714527000000), 1714527000000.0, true},
+		{"empty and missing", bitableText, "", nil, true},
+		{"value and missing", bitableText, "a", nil, false},
+		{"unchecked reads as missing", bitableCheckbox, false, nil, true},
+		{"checked and missing", bitableCheckbox, true, nil, false},
+		{"multi select as set", bitableMultiSelect, []string{"A", "B"}, []interface{}{"B", "A"}, true},
+		{"multi select differs", bitableMultiSelect, []string{"A"}, []interface{}{"A", "B"}, false},
+		{"links as set", bitableDuplexLink, []string{"rec1", "rec2"}, map[string]interface{}{"link_record_ids": []interface{}{"rec2", "rec1"}}, true},
+		{
+			"people by id", bitableUser,
+			[]interface{}{map[string]interface{}{"id": "ou_1"}},
+			[]interface{}{map[string]interface{}{"id": "ou_1", "name": "Ann", "email": "ann@example.com"}},
+			true,
+		},
+		{
+			"attachments by token", bitableAttachment,
+			[]interface{}{map[string]interface{}{"file_token": "a"}},
+			[]interface{}{map[string]interface{}{"file_token": "b"}},
+			false,
+		},
+		{
+			"url by link", bitableURL,
+			map[string]interface{}{"link": "https://example.com", "text": "https://example.com"},
+			map[string]interface{}{"link": "https://example.com", "text": "Example"},
+			true,
+		},
+		{"other types by JSON", bitableLocation, map[string]interface{}{"x": 1.0}, map[string]interface{}{"x": 1.0}, true},
+	}
+	for _, tt := range tests {
+		if got := sameFieldValue(tt.fieldType, tt.want, tt.have); got != tt.same {
+			t.Errorf("%s: sameFieldValue(%#v, %#v) = %v, want %v", tt.name, tt.want, tt.have, got, tt.same)
+		}
+	}
+}
+
+func TestFieldKey(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{"  abc ", "abc"},
+		{10.0, "10"},
+		{int64(10), "10"},
+		{2.5, "2.5"},
+		{[]string{"Done"}, "Done"},
+		{[]interface{}{map[string]interface{}{"text": "a"}, map[string]interface{}{"text": "b"}}, "ab"},
+	}
+	for _, tt := range tests {
+		if got := fieldKey(tt.in); got != tt.want {
+			t.Errorf("fieldKey(%#v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFieldMapperUnchanged(t *testing.T) {
+	name := testField(bitableText)
+	name.FieldName = larkcore.StringPtr("Name")
+	done := testField(bitableCheckbox)
+	done.FieldName = larkcore.StringPtr("Done")
+
+	m := &bitableFieldMapper{fields: map[string]*larkbitable.AppTableFieldForList{"Name": name, "Done": done}}
+	have := map[string]interface{}{"Name": "Ann"}
+
+	tests := []struct {
+		name string
+		want map[string]interface{}
+		same bool
+	}{
+		{"same", map[string]interface{}{"Name": "Ann", "Done": false}, true},
+		{"changed", map[string]interface{}{"Name": "Bob"}, false},
+		{"checked", map[string]interface{}{"Done": true}, false},
+		{"unknown field", map[string]interface{}{"Other": "x"}, false},
+	}
+	for _, tt := range tests {
+		if got := m.unchanged(tt.want, have); got != tt.same {
+			t.Errorf("%s: unchanged(%#v) = %v, want %v", tt.name, tt.want, got, tt.same)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+)
+
+// fetchAllBlocks pages through every block of a Docx file, in document order
+func (h *DocHandler) fetchAllBlocks(ctx context.Context, documentID string) ([]*larkdocx.Block, error) {
+	var blocks []*larkdocx.Block
+	pageToken := ""
+
+	for {
+		inputBuilder := larkdocx.NewListDocumentBlockReqBuilder().
+			DocumentId(documentID).
+			PageSize(500)
+
+		if pageToken != "" {
+			inputBuilder.PageToken(pageToken)
+		}
+
+		resp, err := h.Client.Client.Docx.DocumentBlock.List(ctx, inputBuilder.Build())
+		if err != nil {
+			return nil, err
+		}
+		if !resp.Success() {
+			return nil, fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+		}
+
+		blocks = append(blocks, resp.Data.Items...)
+
+		if !larkcore.BoolValue(resp.Data.HasMore) || larkcore.StringValue(resp.Data.PageToken) == "" {
+			return blocks, nil
+		}
+		pageToken = *resp.Data.PageToken
+	}
+}
+
+// indexBlocks maps block IDs to blocks
+func indexBlocks(blocks []*larkdocx.Block) map[string]*larkdocx.Block {
+	byID := make(map[string]*larkdocx.Block, len(blocks))
+	for _, b := range blocks {
+		if b.BlockId != nil {
+			byID[*b.BlockId] = b
+		}
+	}
+	return byID
+}
+
+// collectSubtree returns the given blocks and all of their descendants, parents before children
+func collectSubtree(byID map[string]*larkdocx.Block, rootIDs []string) []*larkdocx.Block {
+	var out []*larkdocx.Block
+	var walk func(id string)
+	walk = func(id string) {
+		b, ok := byID[id]
+		if !ok {
+			return
+		}
+		out = append(out, b)
+		for _, child := range b.Children {
+			walk(child)
+		}
+	}
+	for _, id := range rootIDs {
+		walk(id)
+	}
+	return out
+}
+
+// blockText returns the text payload of text-like blocks (text, headings, lists, code, quote, todo).
+// It returns nil for blocks without inline text such as tables, images or dividers.
+func blockText(b *larkdocx.Block) *larkdocx.Text {
+	candidates := []*larkdocx.Text{
+		b.Text,
+		b.Heading1, b.Heading2, b.Heading3, b.Heading4, b.Heading5,
+		b.Heading6, b.Heading7, b.Heading8, b.Heading9,
+		b.Bullet, b.Ordered, b.Code, b.Quote, b.Equation, b.Todo,
+	}
+	for _, t := range candidates {
+		if t != nil {
+			return t
+		}
+	}
+	return nil
+}
+
+// headingLevel returns 1-9 for heading blocks and 0 for everything else
+func headingLevel(b *larkdocx.Block) int {
+	headings := []*larkdocx.Text{
+		b.Heading1, b.Heading2, b.Heading3, b.Heading4, b.Heading5,
+		b.Heading6, b.Heading7, b.Heading8, b.Heading9,
+	}
+	for i, t := range headings {
+		if t != nil {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// plainText flattens text elements into a plain string
+func plainText(t *larkdocx.Text) string {
+	if t == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, el := range t.Elements {
+		switch {
+		case el.TextRun != nil:
+			sb.WriteString(larkcore.StringValue(el.TextRun.Content))
+		case el.Equation != nil:
+			sb.WriteString(larkcore.StringValue(el.Equation.Content))
+		case el.MentionDoc != nil:
+			sb.WriteString(larkcore.StringValue(el.MentionDoc.Title))
+		}
+	}
+	return sb.String()
+}
+
+// documentOrder returns the blocks reachable from the document's root page block, depth first,
+// which is the order they appear in when reading the document
+func documentOrder(blocks []*larkdocx.Block, documentID string) []*larkdocx.Block {
+	byID := indexBlocks(blocks)
+	if _, ok := byID[documentID]; !ok {
+		return blocks
+	}
+	return collectSubtree(byID, []string{documentID})
+}
@@ -0,0 +1,788 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"lark-integration-skill/internal/models"
+	"lark-integration-skill/pkg/larkclient"
+
+	"github.com/gin-gonic/gin"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkcalendar "github.com/larksuite/oapi-sdk-go/v3/service/calendar/v4"
+)
+
+const (
+	// maxUsersPerFreeBusy is the most users one batch free/busy query accepts
+	maxUsersPerFreeBusy = 10
+
+	// maxFreeBusyMonths is the longest range one free/busy query may span
+	maxFreeBusyMonths = 3
+)
+
+// eventTimeLayouts are the accepted event time formats besides RFC 3339, read in the event's timezone
+var eventTimeLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+type CalendarHandler struct {
+	Client   *larkclient.ClientWrapper
+	Timezone string // Default IANA timezone for times without an offset, from config
+}
+
+func NewCalendarHandler(client *larkclient.ClientWrapper, timezone string) *CalendarHandler {
+	return &CalendarHandler{Client: client, Timezone: timezone}
+}
+
+// busyInterval is one busy period from a free/busy query
+type busyInterval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// ListCalendars lists the calendars the app can access
+func (h *CalendarHandler) ListCalendars(c *gin.Context) {
+	items := []models.CalendarInfo{}
+	pageToken := ""
+
+	for {
+		builder := larkcalendar.NewListCalendarReqBuilder().
+			PageSize(500)
+
+		if pageToken != "" {
+			builder.PageToken(pageToken)
+		}
+
+		resp, err := h.Client.Client.Calendar.Calendar.List(context.Background(), builder.Build())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+		if !resp.Success() {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+			return
+		}
+
+		for _, cal := range resp.Data.CalendarList {
+			if larkcore.BoolValue(cal.IsDeleted) {
+				continue
+			}
+			items = append(items, models.CalendarInfo{
+				CalendarID:  larkcore.StringValue(cal.CalendarId),
+				Summary:     larkcore.StringValue(cal.Summary),
+				Description: larkcore.StringValue(cal.Description),
+				Type:        larkcore.StringValue(cal.Type),
+				Role:        larkcore.StringValue(cal.Role),
+				Permissions: larkcore.StringValue(cal.Permissions),
+			})
+		}
+
+		if !larkcore.BoolValue(resp.Data.HasMore) || larkcore.StringValue(resp.Data.PageToken) == "" {
+			break
+		}
+		pageToken = *resp.Data.PageToken
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   models.CalendarListResponse{Items: items},
+	})
+}
+
+// CreateCalendarEvent creates an event, optionally with a video meeting, and invites the attendees.
+// The calendar ID "primary" is the app's own primary calendar.
+func (h *CalendarHandler) CreateCalendarEvent(c *gin.Context) {
+	var req models.CreateCalendarEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	calendarID, err := h.calendarID(ctx, c.Param("calendar_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	tz := h.timezone(req.Timezone)
+	start, end, err := eventTimes(req.StartTime, req.EndTime, tz)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	attendees, err := h.toEventAttendees(ctx, req.Attendees)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	event, err := h.insertEvent(ctx, calendarID, buildCalendarEvent(req, start, end), attendees, req.NeedNotification)
+	if err != nil {
+		resp := models.APIResponse{Status: "error", Message: err.Error()}
+		if event.EventID != "" {
+			resp.Data = event
+		}
+		c.JSON(http.StatusInternalServerError, resp)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   event,
+	})
+}
+
+// ListCalendarEvents lists the events of a calendar between start_time and end_time
+func (h *CalendarHandler) ListCalendarEvents(c *gin.Context) {
+	startStr := c.Query("start_time")
+	endStr := c.Query("end_time")
+	if startStr == "" || endStr == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "start_time and end_time are required"})
+		return
+	}
+
+	loc, err := time.LoadLocation(h.timezone(c.Query("timezone")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Invalid timezone: " + err.Error()})
+		return
+	}
+	start, _, err := parseEventTime(startStr, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	end, _, err := parseEventTime(endStr, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	calendarID, err := h.calendarID(ctx, c.Param("calendar_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	items := []models.CalendarEvent{}
+	pageToken := ""
+	for {
+		builder := larkcalendar.NewListCalendarEventReqBuilder().
+			CalendarId(calendarID).
+			StartTime(strconv.FormatInt(start.Unix(), 10)).
+			EndTime(strconv.FormatInt(end.Unix(), 10)).
+			UserIdType("open_id").
+			PageSize(500)
+
+		if pageToken != "" {
+			builder.PageToken(pageToken)
+		}
+
+		resp, err := h.Client.Client.Calendar.CalendarEvent.List(ctx, builder.Build())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+		if !resp.Success() {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+			return
+		}
+
+		for _, e := range resp.Data.Items {
+			if larkcore.StringValue(e.Status) == "cancelled" {
+				continue
+			}
+			items = append(items, toCalendarEvent(e))
+		}
+
+		if !larkcore.BoolValue(resp.Data.HasMore) || larkcore.StringValue(resp.Data.PageToken) == "" {
+			break
+		}
+		pageToken = *resp.Data.PageToken
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   models.CalendarEventListResponse{Items: items},
+	})
+}
+
+// UpdateCalendarEvent changes the given fields of an event and invites any added attendees
+func (h *CalendarHandler) UpdateCalendarEvent(c *gin.Context) {
+	eventID := c.Param("event_id")
+	if eventID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Event ID is required"})
+		return
+	}
+
+	var req models.UpdateCalendarEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if (req.StartTime == nil) != (req.EndTime == nil) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "start_time and end_time must be given together"})
+		return
+	}
+
+	ctx := context.Background()
+	calendarID, err := h.calendarID(ctx, c.Param("calendar_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	attendees, err := h.toEventAttendees(ctx, req.AddAttendees)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	eventBuilder := larkcalendar.NewCalendarEventBuilder()
+	if req.Summary != nil {
+		eventBuilder.Summary(*req.Summary)
+	}
+	if req.Description != nil {
+		eventBuilder.Description(*req.Description)
+	}
+	if req.StartTime != nil {
+		tz := h.timezone(larkcore.StringValue(req.Timezone))
+		start, end, err := eventTimes(*req.StartTime, *req.EndTime, tz)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+		eventBuilder.StartTime(start).EndTime(end)
+	}
+	if req.Location != nil {
+		eventBuilder.Location(larkcalendar.NewEventLocationBuilder().Name(*req.Location).Build())
+	}
+	if req.VideoMeeting != nil {
+		vcType := "no_meeting"
+		if *req.VideoMeeting {
+			vcType = "vc"
+		}
+		eventBuilder.Vchat(larkcalendar.NewVchatBuilder().VcType(vcType).Build())
+	}
+	if req.NeedNotification != nil {
+		eventBuilder.NeedNotification(*req.NeedNotification)
+	}
+
+	input := larkcalendar.NewPatchCalendarEventReqBuilder().
+		CalendarId(calendarID).
+		EventId(eventID).
+		UserIdType("open_id").
+		CalendarEvent(eventBuilder.Build()).
+		Build()
+
+	resp, err := h.Client.Client.Calendar.CalendarEvent.Patch(ctx, input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+
+	event := toCalendarEvent(resp.Data.Event)
+
+	if len(attendees) > 0 {
+		added, err := h.addAttendees(ctx, calendarID, eventID, attendees, req.NeedNotification)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Status:  "error",
+				Message: "Event was updated but inviting attendees failed: " + err.Error(),
+				Data:    event,
+			})
+			return
+		}
+		event.Attendees = added
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   event,
+	})
+}
+
+// CancelCalendarEvent deletes an event, notifying attendees unless notify=false
+func (h *CalendarHandler) CancelCalendarEvent(c *gin.Context) {
+	eventID := c.Param("event_id")
+	if eventID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Event ID is required"})
+		return
+	}
+
+	ctx := context.Background()
+	calendarID, err := h.calendarID(ctx, c.Param("calendar_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	input := larkcalendar.NewDeleteCalendarEventReqBuilder().
+		CalendarId(calendarID).
+		EventId(eventID).
+		NeedNotification(c.DefaultQuery("notify", "true")).
+		Build()
+
+	resp, err := h.Client.Client.Calendar.CalendarEvent.Delete(ctx, input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status:  "success",
+		Message: "Event cancelled",
+	})
+}
+
+// QueryFreeBusy returns the busy periods of each user between time_min and time_max
+func (h *CalendarHandler) QueryFreeBusy(c *gin.Context) {
+	var req models.FreeBusyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	loc, err := time.LoadLocation(h.timezone(""))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: "Invalid calendar timezone: " + err.Error()})
+		return
+	}
+	timeMin, _, err := parseEventTime(req.TimeMin, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	timeMax, _, err := parseEventTime(req.TimeMax, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !timeMax.After(timeMin) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "time_max must be after time_min"})
+		return
+	}
+	if timeMax.After(timeMin.AddDate(0, maxFreeBusyMonths, 0)) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: fmt.Sprintf("time_min to time_max may span at most %d months", maxFreeBusyMonths)})
+		return
+	}
+
+	ctx := context.Background()
+	userIDs, err := h.resolveUsers(ctx, req.Users)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	busy, err := h.freeBusy(ctx, userIDs, timeMin, timeMax)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	items := make([]models.UserFreeBusy, 0, len(req.Users))
+	for i, user := range req.Users {
+		slots := []models.BusySlot{}
+		for _, b := range busy[userIDs[i]] {
+			slots = append(slots, models.BusySlot{
+				StartTime: b.Start.Format(time.RFC3339),
+				EndTime:   b.End.Format(time.RFC3339),
+			})
+		}
+		items = append(items, models.UserFreeBusy{User: user, UserID: userIDs[i], Busy: slots})
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   models.FreeBusyResponse{Items: items},
+	})
+}
+
+// freeBusy queries the busy periods of users (open IDs), maxUsersPerFreeBusy users per request
+func (h *CalendarHandler) freeBusy(ctx context.Context, userIDs []string, timeMin, timeMax time.Time) (map[string][]busyInterval, error) {
+	busy := make(map[string][]busyInterval, len(userIDs))
+
+	for start := 0; start < len(userIDs); start += maxUsersPerFreeBusy {
+		batch := userIDs[start:min(start+maxUsersPerFreeBusy, len(userIDs))]
+
+		input := larkcalendar.NewBatchFreebusyReqBuilder().
+			UserIdType("open_id").
+			Body(larkcalendar.NewBatchFreebusyReqBodyBuilder().
+				TimeMin(timeMin.Format(time.RFC3339)).
+				TimeMax(timeMax.Format(time.RFC3339)).
+				UserIds(batch).
+				OnlyBusy(true).
+				Build()).
+			Build()
+
+		resp, err := h.Client.Client.Calendar.Freebusy.Batch(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		if !resp.Success() {
+			return nil, fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+		}
+
+		for _, list := range resp.Data.FreebusyLists {
+			userID := larkcore.StringValue(list.UserId)
+			for _, item := range list.FreebusyItems {
+				s, err := time.Parse(time.RFC3339, larkcore.StringValue(item.StartTime))
+				if err != nil {
+					return nil, fmt.Errorf("unexpected busy start time: %v", err)
+				}
+				e, err := time.Parse(time.RFC3339, larkcore.StringValue(item.EndTime))
+				if err != nil {
+					return nil, fmt.Errorf("unexpected busy end time: %v", err)
+				}
+				busy[userID] = append(busy[userID], busyInterval{Start: s, End: e})
+			}
+		}
+	}
+
+	return busy, nil
+}
+
+// resolveUsers maps each user, given as an email or open ID, to an open ID
+func (h *CalendarHandler) resolveUsers(ctx context.Context, users []string) ([]string, error) {
+	var emails []string
+	for _, u := range users {
+		if strings.Contains(u, "@") {
+			emails = append(emails, u)
+		}
+	}
+
+	openIDs, err := lookupOpenIDs(ctx, h.Client, emails)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(users))
+	for i, u := range users {
+		if !strings.Contains(u, "@") {
+			ids[i] = u
+			continue
+		}
+		id, ok := openIDs[strings.ToLower(u)]
+		if !ok {
+			return nil, fmt.Errorf("no user found for email %q", u)
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// insertEvent creates an event and invites its attendees. If inviting fails, the created event is returned with the error.
+func (h *CalendarHandler) insertEvent(ctx context.Context, calendarID string, e *larkcalendar.CalendarEvent, attendees []*larkcalendar.CalendarEventAttendee, notify *bool) (models.CalendarEvent, error) {
+	input := larkcalendar.NewCreateCalendarEventReqBuilder().
+		CalendarId(calendarID).
+		UserIdType("open_id").
+		CalendarEvent(e).
+		Build()
+
+	resp, err := h.Client.Client.Calendar.CalendarEvent.Create(ctx, input)
+	if err != nil {
+		return models.CalendarEvent{}, err
+	}
+	if !resp.Success() {
+		return models.CalendarEvent{}, fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+	}
+
+	event := toCalendarEvent(resp.Data.Event)
+	if len(attendees) == 0 {
+		return event, nil
+	}
+
+	added, err := h.addAttendees(ctx, calendarID, event.EventID, attendees, notify)
+	if err != nil {
+		return event, fmt.Errorf("event was created but inviting attendees failed: %v", err)
+	}
+	event.Attendees = added
+	return event, nil
+}
+
+// addAttendees invites attendees to an event and returns the event's full attendee list
+func (h *CalendarHandler) addAttendees(ctx context.Context, calendarID, eventID string, attendees []*larkcalendar.CalendarEventAttendee, notify *bool) ([]models.CalendarAttendee, error) {
+	bodyBuilder := larkcalendar.NewCreateCalendarEventAttendeeReqBodyBuilder().
+		Attendees(attendees)
+
+	if notify != nil {
+		bodyBuilder.NeedNotification(*notify)
+	}
+
+	input := larkcalendar.NewCreateCalendarEventAttendeeReqBuilder().
+		CalendarId(calendarID).
+		EventId(eventID).
+		UserIdType("open_id").
+		Body(bodyBuilder.Build()).
+		Build()
+
+	resp, err := h.Client.Client.Calendar.CalendarEventAttendee.Create(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success() {
+		return nil, fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+	}
+	return toCalendarAttendees(resp.Data.Attendees), nil
+}
+
+// toEventAttendees builds SDK attendees. Users given by email are looked up; emails without a user become third-party attendees.
+func (h *CalendarHandler) toEventAttendees(ctx context.Context, attendees []models.CalendarAttendee) ([]*larkcalendar.CalendarEventAttendee, error) {
+	var emails []string
+	for _, a := range attendees {
+		if a.ID == "" && a.Email != "" && (a.Type == "" || a.Type == "user") {
+			emails = append(emails, a.Email)
+		}
+	}
+	openIDs, err := lookupOpenIDs(ctx, h.Client, emails)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*larkcalendar.CalendarEventAttendee, 0, len(attendees))
+	for i, a := range attendees {
+		builder := larkcalendar.NewCalendarEventAttendeeBuilder().
+			IsOptional(a.Optional)
+
+		attendeeType := a.Type
+		if attendeeType == "" {
+			attendeeType = "user"
+		}
+		switch {
+		case attendeeType == "user" && a.ID != "":
+			builder.Type("user").UserId(a.ID)
+		case attendeeType == "user" && a.Email != "":
+			if id, ok := openIDs[strings.ToLower(a.Email)]; ok {
+				builder.Type("user").UserId(id)
+			} else {
+				builder.Type("third_party").ThirdPartyEmail(a.Email)
+			}
+		case attendeeType == "third_party" && a.Email != "":
+			builder.Type("third_party").ThirdPartyEmail(a.Email)
+		case attendeeType == "chat" && a.ID != "":
+			builder.Type("chat").ChatId(a.ID)
+		case attendeeType == "resource" && a.ID != "":
+			builder.Type("resource").RoomId(a.ID)
+		default:
+			return nil, fmt.Errorf("attendee %d: a %s attendee needs an id or email", i, attendeeType)
+		}
+		out = append(out, builder.Build())
+	}
+	return out, nil
+}
+
+// buildCalendarEvent builds the SDK event for a create request with its converted times
+func buildCalendarEvent(req models.CreateCalendarEventRequest, start, end *larkcalendar.TimeInfo) *larkcalendar.CalendarEvent {
+	builder := larkcalendar.NewCalendarEventBuilder().
+		Summary(req.Summary).
+		StartTime(start).
+		EndTime(end).
+		AttendeeAbility("can_see_others")
+
+	if req.Description != "" {
+		builder.Description(req.Description)
+	}
+	if req.Location != "" {
+		builder.Location(larkcalendar.NewEventLocationBuilder().Name(req.Location).Build())
+	}
+	if req.VideoMeeting {
+		builder.Vchat(larkcalendar.NewVchatBuilder().VcType("vc").Build())
+	}
+	if req.Visibility != "" {
+		builder.Visibility(req.Visibility)
+	}
+	if req.Recurrence != "" {
+		builder.Recurrence(req.Recurrence)
+	}
+	if req.NeedNotification != nil {
+		builder.NeedNotification(*req.NeedNotification)
+	}
+	return builder.Build()
+}
+
+// calendarID resolves "primary" to the app's primary calendar
+func (h *CalendarHandler) calendarID(ctx context.Context, id string) (string, error) {
+	if id != "" && id != "primary" {
+		return id, nil
+	}
+
+	resp, err := h.Client.Client.Calendar.Calendar.Primary(ctx, larkcalendar.NewPrimaryCalendarReqBuilder().Build())
+	if err != nil {
+		return "", err
+	}
+	if !resp.Success() {
+		return "", fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+	}
+	for _, uc := range resp.Data.Calendars {
+		if uc.Calendar != nil && larkcore.StringValue(uc.Calendar.CalendarId) != "" {
+			return *uc.Calendar.CalendarId, nil
+		}
+	}
+	return "", fmt.Errorf("the app has no primary calendar")
+}
+
+// timezone returns tz, or the configured default when tz is empty
+func (h *CalendarHandler) timezone(tz string) string {
+	if tz != "" {
+		return tz
+	}
+	if h.Timezone != "" {
+		return h.Timezone
+	}
+	return "UTC"
+}
+
+// eventTimes converts a start and end to SDK times. Both must be dates (an all-day event) or both date-times.
+func eventTimes(startStr, endStr, tz string) (*larkcalendar.TimeInfo, *larkcalendar.TimeInfo, error) {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid timezone %q", tz)
+	}
+
+	start, startAllDay, err := parseEventTime(startStr, loc)
+	if err != nil {
+		return nil, nil, err
+	}
+	end, endAllDay, err := parseEventTime(endStr, loc)
+	if err != nil {
+		return nil, nil, err
+	}
+	if startAllDay != endAllDay {
+		return nil, nil, fmt.Errorf("start_time and end_time must both be dates or both be date-times")
+	}
+
+	if startAllDay {
+		if end.Before(start) {
+			return nil, nil, fmt.Errorf("end_time is before start_time")
+		}
+		return larkcalendar.NewTimeInfoBuilder().Date(startStr).Build(),
+			larkcalendar.NewTimeInfoBuilder().Date(endStr).Build(),
+			nil
+	}
+
+	if !end.After(start) {
+		return nil, nil, fmt.Errorf("end_time must be after start_time")
+	}
+	return larkcalendar.NewTimeInfoBuilder().Timestamp(strconv.FormatInt(start.Unix(), 10)).Timezone(tz).Build(),
+		larkcalendar.NewTimeInfoBuilder().Timestamp(strconv.FormatInt(end.Unix(), 10)).Timezone(tz).Build(),
+		nil
+}
+
+// parseEventTime parses RFC 3339, a local date-time in loc, a date (reported as all-day) or Unix seconds
+func parseEventTime(s string, loc *time.Location) (time.Time, bool, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+	for _, layout := range eventTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, false, nil
+		}
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, loc); err == nil {
+		return t, true, nil
+	}
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0), false, nil
+	}
+	return time.Time{}, false, fmt.Errorf("%q is not a time; use RFC 3339 such as 2024-05-01T09:30:00+08:00", s)
+}
+
+// fromTimeInfo renders an SDK time as RFC 3339 in its timezone, or as the date of an all-day event
+func fromTimeInfo(ti *larkcalendar.TimeInfo) (string, bool) {
+	if ti == nil {
+		return "", false
+	}
+	if date := larkcore.StringValue(ti.Date); date != "" {
+		return date, true
+	}
+
+	sec, err := strconv.ParseInt(larkcore.StringValue(ti.Timestamp), 10, 64)
+	if err != nil {
+		return larkcore.StringValue(ti.Timestamp), false
+	}
+	t := time.Unix(sec, 0)
+	if loc, err := time.LoadLocation(larkcore.StringValue(ti.Timezone)); err == nil {
+		t = t.In(loc)
+	}
+	return t.Format(time.RFC3339), false
+}
+
+func toCalendarEvent(e *larkcalendar.CalendarEvent) models.CalendarEvent {
+	if e == nil {
+		return models.CalendarEvent{}
+	}
+
+	start, allDay := fromTimeInfo(e.StartTime)
+	end, _ := fromTimeInfo(e.EndTime)
+
+	event := models.CalendarEvent{
+		EventID:     larkcore.StringValue(e.EventId),
+		CalendarID:  larkcore.StringValue(e.OrganizerCalendarId),
+		Summary:     larkcore.StringValue(e.Summary),
+		Description: larkcore.StringValue(e.Description),
+		StartTime:   start,
+		EndTime:     end,
+		AllDay:      allDay,
+		Status:      larkcore.StringValue(e.Status),
+		Visibility:  larkcore.StringValue(e.Visibility),
+		Recurrence:  larkcore.StringValue(e.Recurrence),
+		AppLink:     larkcore.StringValue(e.AppLink),
+		Attendees:   toCalendarAttendees(e.Attendees),
+	}
+	if e.StartTime != nil {
+		event.Timezone = larkcore.StringValue(e.StartTime.Timezone)
+	}
+	if e.Location != nil {
+		event.Location = larkcore.StringValue(e.Location.Name)
+	}
+	if e.Vchat != nil {
+		event.MeetingURL = larkcore.StringValue(e.Vchat.MeetingUrl)
+	}
+	return event
+}
+
+func toCalendarAttendees(attendees []*larkcalendar.CalendarEventAttendee) []models.CalendarAttendee {
+	if len(attendees) == 0 {
+		return nil
+	}
+
+	out := make([]models.CalendarAttendee, 0, len(attendees))
+	for _, a := range attendees {
+		attendee := models.CalendarAttendee{
+			Type:        larkcore.StringValue(a.Type),
+			DisplayName: larkcore.StringValue(a.DisplayName),
+			RsvpStatus:  larkcore.StringValue(a.RsvpStatus),
+			Optional:    larkcore.BoolValue(a.IsOptional),
+		}
+		switch attendee.Type {
+		case "user":
+			attendee.ID = larkcore.StringValue(a.UserId)
+		case "chat":
+			attendee.ID = larkcore.StringValue(a.ChatId)
+		case "resource":
+			attendee.ID = larkcore.StringValue(a.RoomId)
+		case "third_party":
+			attendee.Email = larkcore.StringValue(a.ThirdPartyEmail)
+		}
+		out = append(out, attendee)
+	}
+	return out
+}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"lark-integration-skill/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxSlotSearchDays limits how many days one slot search covers
+	maxSlotSearchDays = 60
+
+	// maxSlotResults caps the candidate slots returned
+	maxSlotResults = 50
+)
+
+// slotCandidate is a time where every required attendee is free
+type slotCandidate struct {
+	Start       time.Time
+	End         time.Time
+	Available   []string
+	Unavailable []string
+}
+
+// FindMeetingSlots finds times within working hours when every attendee is free.
+// Slots where more optional attendees are free rank first, then earlier slots.
+// With book set, an event with all attendees is created in the top slot.
+func (h *CalendarHandler) FindMeetingSlots(c *gin.Context) {
+	var req models.FindSlotsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	if req.DurationMinutes <= 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "duration_minutes must be positive"})
+		return
+	}
+	if req.StepMinutes <= 0 {
+		req.StepMinutes = 30
+	}
+	if req.MaxResults <= 0 {
+		req.MaxResults = 5
+	}
+	req.MaxResults = min(req.MaxResults, maxSlotResults)
+	if req.WorkStart == "" {
+		req.WorkStart = "09:00"
+	}
+	if req.WorkEnd == "" {
+		req.WorkEnd = "18:00"
+	}
+
+	tz := h.timezone(req.Timezone)
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: fmt.Sprintf("Invalid timezone %q", tz)})
+		return
+	}
+
+	firstDay, err := time.ParseInLocation("2006-01-02", req.StartDate, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "start_date must look like 2006-01-02"})
+		return
+	}
+	lastDay, err := time.ParseInLocation("2006-01-02", req.EndDate, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "end_date must look like 2006-01-02"})
+		return
+	}
+	// Rounding absorbs a daylight saving shift between the two dates
+	days := int(lastDay.Sub(firstDay).Hours()/24+0.5) + 1
+	if lastDay.Before(firstDay) || days > maxSlotSearchDays {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: fmt.Sprintf("end_date must be on or after start_date and within %d days", maxSlotSearchDays)})
+		return
+	}
+
+	workStart, err := time.Parse("15:04", req.WorkStart)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "work_start must look like 09:00"})
+		return
+	}
+	workEnd, err := time.Parse("15:04", req.WorkEnd)
+	if err != nil || !workEnd.After(workStart) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "work_end must look like 18:00 and be after work_start"})
+		return
+	}
+
+	// Required attendees first; optional ones that are also required count as required
+	required := map[string]bool{}
+	users := []string{}
+	for _, u := range req.Attendees {
+		if _, ok := required[u]; !ok {
+			required[u] = true
+			users = append(users, u)
+		}
+	}
+	for _, u := range req.OptionalAttendees {
+		if _, ok := required[u]; !ok {
+			required[u] = false
+			users = append(users, u)
+		}
+	}
+
+	ctx := context.Background()
+	userIDs, err := h.resolveUsers(ctx, users)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	rangeStart := atClock(firstDay, workStart)
+	rangeEnd := atClock(firstDay.AddDate(0, 0, days-1), workEnd)
+	busy, err := h.freeBusy(ctx, userIDs, rangeStart, rangeEnd)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	duration := time.Duration(req.DurationMinutes) * time.Minute
+	step := time.Duration(req.StepMinutes) * time.Minute
+	now := time.Now()
+
+	var candidates []slotCandidate
+	for d := 0; d < days; d++ {
+		day := firstDay.AddDate(0, 0, d)
+		if !req.IncludeWeekends && (day.Weekday() == time.Saturday || day.Weekday() == time.Sunday) {
+			continue
+		}
+
+		dayEnd := atClock(day, workEnd)
+		for start := atClock(day, workStart); !start.Add(duration).After(dayEnd); start = start.Add(step) {
+			if start.Before(now) {
+				continue
+			}
+			end := start.Add(duration)
+
+			slot, ok := slotAvailability(users, userIDs, required, busy, start, end)
+			if ok {
+				candidates = append(candidates, slot)
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return len(candidates[i].Available) > len(candidates[j].Available)
+	})
+	if len(candidates) > req.MaxResults {
+		candidates = candidates[:req.MaxResults]
+	}
+
+	data := models.FindSlotsResponse{Slots: make([]models.FreeSlot, 0, len(candidates))}
+	for _, s := range candidates {
+		data.Slots = append(data.Slots, models.FreeSlot{
+			StartTime:   s.Start.In(loc).Format(time.RFC3339),
+			EndTime:     s.End.In(loc).Format(time.RFC3339),
+			Available:   s.Available,
+			Unavailable: s.Unavailable,
+		})
+	}
+
+	if req.Book == nil {
+		c.JSON(http.StatusOK, models.APIResponse{
+			Status: "success",
+			Data:   data,
+		})
+		return
+	}
+
+	if len(candidates) == 0 {
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Status:  "error",
+			Message: "No slot has every attendee free, nothing was booked",
+			Data:    data,
+		})
+		return
+	}
+
+	attendees := make([]models.CalendarAttendee, 0, len(users))
+	for i, u := range users {
+		attendees = append(attendees, models.CalendarAttendee{Type: "user", ID: userIDs[i], Optional: !required[u]})
+	}
+
+	booked, err := h.bookSlot(ctx, req.Book, candidates[0], attendees, tz)
+	if err != nil {
+		if booked.EventID != "" {
+			data.Booked = &booked
+		}
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Status:  "error",
+			Message: "Booking failed: " + err.Error(),
+			Data:    data,
+		})
+		return
+	}
+	data.Booked = &booked
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   data,
+	})
+}
+
+// slotAvailability checks a slot against each user's busy periods. It fails when a required user is busy;
+// otherwise it lists which optional users are free.
+func slotAvailability(users, userIDs []string, required map[string]bool, busy map[string][]busyInterval, start, end time.Time) (slotCandidate, bool) {
+	slot := slotCandidate{Start: start, End: end}
+
+	for i, u := range users {
+		free := true
+		for _, b := range busy[userIDs[i]] {
+			if b.Start.Before(end) && b.End.After(start) {
+				free = false
+				break
+			}
+		}
+
+		switch {
+		case required[u] && !free:
+			return slotCandidate{}, false
+		case required[u]:
+		case free:
+			slot.Available = append(slot.Available, u)
+		default:
+			slot.Unavailable = append(slot.Unavailable, u)
+		}
+	}
+	return slot, true
+}
+
+// bookSlot creates an event in the slot and invites the attendees
+func (h *CalendarHandler) bookSlot(ctx context.Context, book *models.BookSlotRequest, slot slotCandidate, attendees []models.CalendarAttendee, tz string) (models.CalendarEvent, error) {
+	calendarID, err := h.calendarID(ctx, book.CalendarID)
+	if err != nil {
+		return models.CalendarEvent{}, err
+	}
+
+	req := models.CreateCalendarEventRequest{
+		Summary:      book.Summary,
+		Description:  book.Description,
+		StartTime:    slot.Start.Format(time.RFC3339),
+		EndTime:      slot.End.Format(time.RFC3339),
+		Timezone:     tz,
+		Location:     book.Location,
+		VideoMeeting: book.VideoMeeting,
+		Attendees:    attendees,
+	}
+
+	start, end, err := eventTimes(req.StartTime, req.EndTime, tz)
+	if err != nil {
+		return models.CalendarEvent{}, err
+	}
+	eventAttendees, err := h.toEventAttendees(ctx, req.Attendees)
+	if err != nil {
+		return models.CalendarEvent{}, err
+	}
+
+	return h.insertEvent(ctx, calendarID, buildCalendarEvent(req, start, end), eventAttendees, nil)
+}
+
+// atClock returns day at the wall-clock time of clock, in day's location
+func atClock(day, clock time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, day.Location())
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAtClock(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	clock, _ := time.Parse("15:04", "09:30")
+
+	tests := []struct {
+		day  time.Time
+		want time.Time
+	}{
+		{time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)},
+		{time.Date(2024, 5, 1, 23, 59, 0, 0, shanghai), time.Date(2024, 5, 1, 9, 30, 0, 0, shanghai)},
+	}
+	for _, tt := range tests {
+		if got := atClock(tt.day, clock); !got.Equal(tt.want) || got.Location() != tt.want.Location() {
+			t.Errorf("atClock(%v) = %v, want %v", tt.day, got, tt.want)
+		}
+	}
+}
+
+func TestSlotAvailability(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 5, 1, hour, minute, 0, 0, time.UTC)
+	}
+
+	users := []string{"ann@example.com", "bob@example.com", "cat@example.com"}
+	userIDs := []string{"ou_ann", "ou_bob", "ou_cat"}
+	required := map[string]bool{"ann@example.com": true, "bob@example.com": false, "cat@example.com": false}
+	busy := map[string][]busyInterval{
+		"ou_ann": {{Start: at(9, 0), End: at(10, 0)}},
+		"ou_bob": {{Start: at(10, 30), End: at(11, 0)}},
+	}
+
+	tests := []struct {
+		name        string
+		start, end  time.Time
+		ok          bool
+		available   []string
+		unavailable []string
+	}{
+		{"required user busy", at(9, 30), at(10, 30), false, nil, nil},
+		{"ends when a busy period starts", at(10, 0), at(10, 30), true, []string{"bob@example.com", "cat@example.com"}, nil},
+		{"optional user busy", at(10, 0), at(11, 0), true, []string{"cat@example.com"}, []string{"bob@example.com"}},
+		{"starts when a busy period ends", at(11, 0), at(12, 0), true, []string{"bob@example.com", "cat@example.com"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slot, ok := slotAvailability(users, userIDs, required, busy, tt.start, tt.end)
+			if ok != tt.ok {
+				t.Fatalf("slotAvailability ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if !reflect.DeepEqual(slot.Available, tt.available) || !reflect.DeepEqual(slot.Unavailable, tt.unavailable) {
+				t.Errorf("slotAvailability = available %v, unavailable %v, want %v, %v", slot.Available, slot.Unavailable, tt.available, tt.unavailable)
+			}
+			if !slot.Start.Equal(tt.start) || !slot.End.Equal(tt.end) {
+				t.Errorf("slot = %v-%v, want %v-%v", slot.Start, slot.End, tt.start, tt.end)
+			}
+		})
+	}
+}
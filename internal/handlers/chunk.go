@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"lark-integration-skill/internal/models"
+
+	"github.com/gin-gonic/gin"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+)
+
+const (
+	defaultChunkTokens = 1000
+	minChunkTokens     = 50
+)
+
+// GetDocumentChunks splits a Docx file into Markdown chunks that fit a token budget.
+// Chunks break at headings, and otherwise between top-level blocks (paragraphs, lists, tables);
+// a single block larger than the budget is split on line boundaries, tables by row with the header repeated.
+func (h *DocHandler) GetDocumentChunks(c *gin.Context) {
+	docToken := c.Param("doc_token")
+	if docToken == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Doc Token is required"})
+		return
+	}
+
+	maxTokens := defaultChunkTokens
+	if maxTokensStr := c.Query("max_tokens"); maxTokensStr != "" {
+		n, err := strconv.Atoi(maxTokensStr)
+		if err != nil || n < minChunkTokens {
+			c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "max_tokens must be an integer of at least 50"})
+			return
+		}
+		maxTokens = n
+	}
+
+	blocks, err := h.fetchAllBlocks(context.Background(), docToken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data: models.DocChunksResponse{
+			DocToken:  docToken,
+			MaxTokens: maxTokens,
+			Chunks:    chunkDocument(docToken, blocks, maxTokens),
+		},
+	})
+}
+
+// chunker accumulates rendered blocks into chunks
+type chunker struct {
+	maxTokens int
+	chunks    []models.DocChunk
+	current   *models.DocChunk
+	parts     []string
+}
+
+func (ck *chunker) add(text, blockID string, headingPath []string) {
+	tokens := estimateTokens(text) + 1 // Separator between parts
+	if ck.current != nil && ck.current.Tokens+tokens > ck.maxTokens {
+		ck.flush()
+	}
+	if ck.current == nil {
+		ck.current = &models.DocChunk{
+			HeadingPath:  append([]string{}, headingPath...),
+			StartBlockID: blockID,
+		}
+	}
+	ck.parts = append(ck.parts, text)
+	ck.current.EndBlockID = blockID
+	ck.current.Tokens += tokens
+}
+
+func (ck *chunker) flush() {
+	if ck.current == nil {
+		return
+	}
+	ck.current.Index = len(ck.chunks)
+	ck.current.Text = strings.Join(ck.parts, "\n\n")
+	ck.current.Tokens = estimateTokens(ck.current.Text)
+	ck.chunks = append(ck.chunks, *ck.current)
+	ck.current = nil
+	ck.parts = nil
+}
+
+func chunkDocument(docToken string, blocks []*larkdocx.Block, maxTokens int) []models.DocChunk {
+	byID := indexBlocks(blocks)
+	root, ok := byID[docToken]
+	if !ok {
+		return []models.DocChunk{}
+	}
+
+	renderer := &markdownRenderer{byID: byID}
+	ck := &chunker{maxTokens: maxTokens}
+
+	type heading struct {
+		level int
+		text  string
+	}
+	var headings []heading
+	headingPath := func() []string {
+		path := make([]string, 0, len(headings))
+		for _, hd := range headings {
+			path = append(path, hd.text)
+		}
+		return path
+	}
+
+	for _, id := range root.Children {
+		b, ok := byID[id]
+		if !ok {
+			continue
+		}
+
+		// Every heading starts a new chunk
+		if level := headingLevel(b); level > 0 {
+			ck.flush()
+			for len(headings) > 0 && headings[len(headings)-1].level >= level {
+				headings = headings[:len(headings)-1]
+			}
+			headings = append(headings, heading{level: level, text: strings.TrimSpace(plainText(blockText(b)))})
+		}
+
+		text := renderer.renderBlock(id, 0)
+		if text == "" {
+			continue
+		}
+
+		blockID := larkcore.StringValue(b.BlockId)
+		if estimateTokens(text) <= maxTokens {
+			ck.add(text, blockID, headingPath())
+			continue
+		}
+
+		// Oversized block: split it by lines and let the pieces fill chunks
+		pieces := splitToBudget(text, maxTokens)
+		if b.Table != nil {
+			pieces = splitTable(text, maxTokens)
+		}
+		for _, piece := range pieces {
+			ck.add(piece, blockID, headingPath())
+		}
+	}
+	ck.flush()
+
+	if ck.chunks == nil {
+		return []models.DocChunk{}
+	}
+	return ck.chunks
+}
+
+// splitToBudget breaks text into pieces under maxTokens, on line boundaries where possible
+func splitToBudget(text string, maxTokens int) []string {
+	var pieces []string
+	var current []string
+	currentTokens := 0
+
+	for _, line := range strings.Split(text, "\n") {
+		for _, part := range splitLine(line, maxTokens) {
+			tokens := estimateTokens(part) + 1
+			if currentTokens+tokens > maxTokens && len(current) > 0 {
+				pieces = append(pieces, strings.Join(current, "\n"))
+				current, currentTokens = nil, 0
+			}
+			current = append(current, part)
+			currentTokens += tokens
+		}
+	}
+	if len(current) > 0 {
+		pieces = append(pieces, strings.Join(current, "\n"))
+	}
+	return pieces
+}
+
+// splitTable splits a Markdown table by rows and repeats the header row and separator
+// at the top of every piece, so each piece reads as a table on its own
+func splitTable(text string, maxTokens int) []string {
+	lines := strings.Split(text, "\n")
+	if len(lines) < 3 {
+		return splitToBudget(text, maxTokens)
+	}
+
+	header := strings.Join(lines[:2], "\n")
+	rowBudget := maxTokens - estimateTokens(header) - 1
+	if rowBudget < 1 {
+		// The header alone fills the budget; fall back to plain line splitting
+		return splitToBudget(text, maxTokens)
+	}
+
+	pieces := splitToBudget(strings.Join(lines[2:], "\n"), rowBudget)
+	for i, piece := range pieces {
+		pieces[i] = header + "\n" + piece
+	}
+	return pieces
+}
+
+// splitLine hard-splits a single line that is over budget on its own
+func splitLine(line string, maxTokens int) []string {
+	if estimateTokens(line) <= maxTokens {
+		return []string{line}
+	}
+
+	var parts []string
+	var current []rune
+	ascii, other := 0, 0
+	for _, r := range line {
+		nextAscii, nextOther := ascii, other
+		if r < utf8.RuneSelf {
+			nextAscii++
+		} else {
+			nextOther++
+		}
+		if (nextAscii+3)/4+nextOther > maxTokens && len(current) > 0 {
+			parts = append(parts, string(current))
+			current = nil
+			nextAscii, nextOther = nextAscii-ascii, nextOther-other
+		}
+		current = append(current, r)
+		ascii, other = nextAscii, nextOther
+	}
+	if len(current) > 0 {
+		parts = append(parts, string(current))
+	}
+	return parts
+}
+
+// estimateTokens approximates a tokenizer: about four ASCII characters per token,
+// and one token per character for everything else (CJK text in particular)
+func estimateTokens(s string) int {
+	ascii, other := 0, 0
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+3)/4 + other
+}
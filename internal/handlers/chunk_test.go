@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"a", 1},
+		{"abcd", 1},
+		{"abcde", 2},
+		{"你好", 2},
+		{"ab你好", 3},
+	}
+	for _, tt := range tests {
+		if got := estimateTokens(tt.in); got != tt.want {
+			t.Errorf("estimateTokens(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSplitToBudget(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		maxTokens int
+		want      []string
+	}{
+		{
+			name:      "fits",
+			text:      "one\ntwo",
+			maxTokens: 10,
+			want:      []string{"one\ntwo"},
+		},
+		{
+			name:      "splits on lines",
+			text:      "aaaa\nbbbb\ncccc",
+			maxTokens: 4,
+			want:      []string{"aaaa\nbbbb", "cccc"},
+		},
+		{
+			name:      "hard-splits a long line",
+			text:      strings.Repeat("a", 12),
+			maxTokens: 1,
+			want:      []string{"aaaa", "aaaa", "aaaa"},
+		},
+		{
+			name:      "hard-splits CJK per character",
+			text:      "你好世界",
+			maxTokens: 2,
+			want:      []string{"你好", "世界"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitToBudget(tt.text, tt.maxTokens)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+				t.Errorf("splitToBudget(%q, %d) = %q, want %q", tt.text, tt.maxTokens, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitToBudgetKeepsTextUnderBudget(t *testing.T) {
+	text := "first line\n" + strings.Repeat("word ", 50) + "\nlast line"
+	pieces := splitToBudget(text, 8)
+
+	var rebuilt []string
+	for _, p := range pieces {
+		if n := estimateTokens(p); n > 8 {
+			t.Errorf("piece %q is %d tokens, over budget", p, n)
+		}
+		rebuilt = append(rebuilt, strings.Split(p, "\n")...)
+	}
+	if got := strings.Join(rebuilt, ""); got != strings.ReplaceAll(text, "\n", "") {
+		t.Errorf("pieces lost text: got %q", got)
+	}
+}
+
+func TestSplitTable(t *testing.T) {
+	header := "| Name | Role |\n| --- | --- |"
+	rows := []string{"| Ann | Dev |", "| Bob | Ops |", "| Cat | QA |", "| Dan | PM |"}
+	text := header + "\n" + strings.Join(rows, "\n")
+
+	pieces := splitTable(text, 14)
+	if len(pieces) < 2 {
+		t.Fatalf("splitTable returned %d piece(s), want the table split", len(pieces))
+	}
+
+	var rebuilt []string
+	for _, p := range pieces {
+		if !strings.HasPrefix(p, header+"\n") {
+			t.Errorf("piece %q does not start with the header row", p)
+		}
+		if n := estimateTokens(p); n > 14 {
+			t.Errorf("piece %q is %d tokens, over budget", p, n)
+		}
+		rebuilt = append(rebuilt, strings.Split(strings.TrimPrefix(p, header+"\n"), "\n")...)
+	}
+	if got := strings.Join(rebuilt, "\n"); got != strings.Join(rows, "\n") {
+		t.Errorf("rows across pieces = %q, want %q", got, strings.Join(rows, "\n"))
+	}
+
+	// A header that fills the budget on its own falls back to line splitting
+	if got := splitTable(text, 3); len(got) < 2 || strings.HasPrefix(got[1], header) {
+		t.Errorf("splitTable with a tiny budget = %q, want plain line pieces", got)
+	}
+}
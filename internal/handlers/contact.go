@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"lark-integration-skill/pkg/larkclient"
+
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkcontact "github.com/larksuite/oapi-sdk-go/v3/service/contact/v3"
+)
+
+const (
+	// maxEmailsPerLookup is the most emails the contact API resolves in one call
+	maxEmailsPerLookup = 50
+
+	// maxUsersPerLookup is the most user IDs the contact API returns in one call
+	maxUsersPerLookup = 50
+)
+
+// lookupOpenIDs resolves emails to open IDs, keyed by lower-cased email.
+// Emails that match no user visible to the app are left out of the result.
+func lookupOpenIDs(ctx context.Context, client *larkclient.ClientWrapper, emails []string) (map[string]string, error) {
+	openIDs := make(map[string]string, len(emails))
+
+	for start := 0; start < len(emails); start += maxEmailsPerLookup {
+		batch := emails[start:min(start+maxEmailsPerLookup, len(emails))]
+
+		input := larkcontact.NewBatchGetIdUserReqBuilder().
+			UserIdType("open_id").
+			Body(larkcontact.NewBatchGetIdUserReqBodyBuilder().
+				Emails(batch).
+				Build()).
+			Build()
+
+		resp, err := client.Client.Contact.User.BatchGetId(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		if !resp.Success() {
+			return nil, fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+		}
+
+		for _, u := range resp.Data.UserList {
+			if id := larkcore.StringValue(u.UserId); id != "" {
+				openIDs[strings.ToLower(larkcore.StringValue(u.Email))] = id
+			}
+		}
+	}
+
+	return openIDs, nil
+}
+
+// lookupUserNames resolves open IDs to display names.
+// Users the app cannot see are left out of the result.
+func lookupUserNames(ctx context.Context, client *larkclient.ClientWrapper, openIDs []string) (map[string]string, error) {
+	names := make(map[string]string, len(openIDs))
+
+	for start := 0; start < len(openIDs); start += maxUsersPerLookup {
+		batch := openIDs[start:min(start+maxUsersPerLookup, len(openIDs))]
+
+		input := larkcontact.NewBatchUserReqBuilder().
+			UserIds(batch).
+			UserIdType("open_id").
+			Build()
+
+		resp, err := client.Client.Contact.User.Batch(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		if !resp.Success() {
+			return nil, fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+		}
+
+		for _, u := range resp.Data.Items {
+			if name := larkcore.StringValue(u.Name); name != "" {
+				names[larkcore.StringValue(u.OpenId)] = name
+			}
+		}
+	}
+
+	return names, nil
+}
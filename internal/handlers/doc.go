@@ -4,21 +4,29 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"lark-integration-skill/internal/models"
 	"lark-integration-skill/pkg/larkclient"
 
 	"github.com/gin-gonic/gin"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
 	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
 	larkdrive "github.com/larksuite/oapi-sdk-go/v3/service/drive/v1"
 )
 
 type DocHandler struct {
-	Client *larkclient.ClientWrapper
+	Client     *larkclient.ClientWrapper
+	ExportJobs *ExportJobStore
+	Snapshots  *SnapshotStore
 }
 
 func NewDocHandler(client *larkclient.ClientWrapper) *DocHandler {
-	return &DocHandler{Client: client}
+	return &DocHandler{
+		Client:     client,
+		ExportJobs: NewExportJobStore(),
+		Snapshots:  NewSnapshotStore(),
+	}
 }
 
 // CreateDoc creates a new Docx file
@@ -151,11 +159,13 @@ func (h *DocHandler) GetDocumentRawContent(c *gin.Context) {
 	})
 }
 
-// GetDocumentBlocks retrieves all blocks (or paginated) of a Docx file
+// GetDocumentBlocks retrieves all blocks (or paginated) of a Docx file.
+// An optional revision_id query param fetches the blocks as of that revision.
 func (h *DocHandler) GetDocumentBlocks(c *gin.Context) {
 	docToken := c.Param("doc_token")
 	pageToken := c.Query("page_token")
 	pageSizeStr := c.DefaultQuery("page_size", "500") // Default to 500 blocks
+	revisionIDStr := c.Query("revision_id")
 
 	if docToken == "" {
 		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Doc Token is required"})
@@ -166,13 +176,21 @@ func (h *DocHandler) GetDocumentBlocks(c *gin.Context) {
 	var pageSize int
 	fmt.Sscanf(pageSizeStr, "%d", &pageSize)
 
-	input := larkdocx.NewListDocumentBlockReqBuilder().
+	inputBuilder := larkdocx.NewListDocumentBlockReqBuilder().
 		DocumentId(docToken).
 		PageSize(pageSize).
-		PageToken(pageToken).
-		Build()
+		PageToken(pageToken)
+
+	if revisionIDStr != "" {
+		revisionID, err := strconv.Atoi(revisionIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "revision_id must be an integer"})
+			return
+		}
+		inputBuilder.DocumentRevisionId(revisionID)
+	}
 
-	resp, err := h.Client.Client.Docx.DocumentBlock.List(context.Background(), input)
+	resp, err := h.Client.Client.Docx.DocumentBlock.List(context.Background(), inputBuilder.Build())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
 		return
@@ -241,10 +259,32 @@ func (h *DocHandler) CreateDocBlock(c *gin.Context) {
 		return
 	}
 
+	// Record the created blocks so the operation can be undone
+	createdIDs := make([]string, 0, len(resp.Data.Children))
+	for _, b := range resp.Data.Children {
+		if id := larkcore.StringValue(b.BlockId); id != "" {
+			createdIDs = append(createdIDs, id)
+		}
+	}
+	if len(createdIDs) > 0 {
+		index := -1
+		if req.Index != nil {
+			index = *req.Index
+		}
+		h.Snapshots.Record(&docOperation{
+			Kind:       OperationCreate,
+			DocumentID: documentID,
+			BlockID:    blockID,
+			Index:      index,
+			BlockIDs:   createdIDs,
+		})
+	}
+
 	c.JSON(http.StatusOK, models.APIResponse{
 		Status: "success",
 		Data: models.CreateDocBlockResponse{
-			Blocks: resp.Data.Children,
+			Blocks:             resp.Data.Children,
+			DocumentRevisionId: resp.Data.DocumentRevisionId,
 		},
 	})
 }
@@ -268,6 +308,18 @@ func (h *DocHandler) UpdateDocBlock(c *gin.Context) {
 	// Ensure BlockId is set correctly from URL param if not in body, though typically body has details
 	req.UpdateBlockRequest.BlockId = &blockID
 
+	// Snapshot the block before changing it so the update can be undone
+	snapshotIDs, snapshot, err := h.snapshotBlocks(context.Background(), documentID, func(byID map[string]*larkdocx.Block) ([]string, error) {
+		if _, ok := byID[blockID]; !ok {
+			return nil, fmt.Errorf("%w: %s", errSnapshotBlockNotFound, blockID)
+		}
+		return []string{blockID}, nil
+	})
+	if err != nil {
+		c.JSON(snapshotErrorStatus(err), models.APIResponse{Status: "error", Message: fmt.Sprintf("Failed to snapshot block: %v", err)})
+		return
+	}
+
 	input := larkdocx.NewPatchDocumentBlockReqBuilder().
 		DocumentId(documentID).
 		BlockId(blockID).
@@ -284,10 +336,19 @@ func (h *DocHandler) UpdateDocBlock(c *gin.Context) {
 		return
 	}
 
+	h.Snapshots.Record(&docOperation{
+		Kind:       OperationUpdate,
+		DocumentID: documentID,
+		BlockID:    blockID,
+		BlockIDs:   snapshotIDs,
+		Blocks:     snapshot,
+	})
+
 	c.JSON(http.StatusOK, models.APIResponse{
 		Status: "success",
 		Data: models.UpdateDocBlockResponse{
-			Block: resp.Data.Block,
+			Block:              resp.Data.Block,
+			DocumentRevisionId: resp.Data.DocumentRevisionId,
 		},
 	})
 }
@@ -402,6 +463,30 @@ func (h *DocHandler) DeleteDocBlockChildren(c *gin.Context) {
 		bodyBuilder.EndIndex(*req.EndIndex)
 	}
 
+	// Snapshot the children about to be removed so the delete can be undone
+	startIndex := 0
+	snapshotIDs, snapshot, err := h.snapshotBlocks(context.Background(), documentID, func(byID map[string]*larkdocx.Block) ([]string, error) {
+		parent, ok := byID[blockID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", errSnapshotBlockNotFound, blockID)
+		}
+		endIndex := len(parent.Children)
+		if req.StartIndex != nil {
+			startIndex = *req.StartIndex
+		}
+		if req.EndIndex != nil {
+			endIndex = *req.EndIndex
+		}
+		if startIndex < 0 || endIndex > len(parent.Children) || startIndex > endIndex {
+			return nil, fmt.Errorf("%w: [%d, %d) for %d children", errSnapshotRange, startIndex, endIndex, len(parent.Children))
+		}
+		return parent.Children[startIndex:endIndex], nil
+	})
+	if err != nil {
+		c.JSON(snapshotErrorStatus(err), models.APIResponse{Status: "error", Message: fmt.Sprintf("Failed to snapshot blocks: %v", err)})
+		return
+	}
+
 	input := larkdocx.NewBatchDeleteDocumentBlockChildrenReqBuilder().
 		DocumentId(documentID).
 		BlockId(blockID).
@@ -418,6 +503,15 @@ func (h *DocHandler) DeleteDocBlockChildren(c *gin.Context) {
 		return
 	}
 
+	h.Snapshots.Record(&docOperation{
+		Kind:       OperationDelete,
+		DocumentID: documentID,
+		BlockID:    blockID,
+		Index:      startIndex,
+		BlockIDs:   snapshotIDs,
+		Blocks:     snapshot,
+	})
+
 	c.JSON(http.StatusOK, models.APIResponse{
 		Status: "success",
 		Data: models.DeleteDocBlockChildrenResponse{
@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+
+	"lark-integration-skill/internal/models"
+
+	"github.com/gin-gonic/gin"
+	larkdrive "github.com/larksuite/oapi-sdk-go/v3/service/drive/v1"
+)
+
+// Export job statuses as reported by this service
+const (
+	ExportStatusProcessing = "processing"
+	ExportStatusSucceeded  = "succeeded"
+	ExportStatusFailed     = "failed"
+)
+
+// exportFormats lists the file extensions Drive can export for each document type
+var exportFormats = map[string][]string{
+	"docx":  {"pdf", "docx"},
+	"sheet": {"xlsx", "csv"},
+}
+
+var exportContentTypes = map[string]string{
+	"pdf":  "application/pdf",
+	"docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"csv":  "text/csv",
+}
+
+// exportJobTTL is how long a job stays in the table after it was created.
+// Drive keeps export results for a limited time, so older jobs can't be downloaded anyway.
+const exportJobTTL = 24 * time.Hour
+
+// ExportJobStore is the local job table for Drive export tasks, keyed by export ticket
+type ExportJobStore struct {
+	mu   sync.RWMutex
+	jobs map[string]*models.ExportJobResponse
+}
+
+func NewExportJobStore() *ExportJobStore {
+	return &ExportJobStore{jobs: make(map[string]*models.ExportJobResponse)}
+}
+
+// Put records a job and evicts the ones older than exportJobTTL
+func (s *ExportJobStore) Put(job *models.ExportJobResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := time.Now().Add(-exportJobTTL).Unix()
+	for id, j := range s.jobs {
+		if j.CreateTime < cutoff {
+			delete(s.jobs, id)
+		}
+	}
+	s.jobs[job.JobID] = job
+}
+
+// Get returns a copy of the job so callers can't race with updates
+func (s *ExportJobStore) Get(jobID string) (models.ExportJobResponse, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	job, ok := s.jobs[jobID]
+	if !ok || job.CreateTime < time.Now().Add(-exportJobTTL).Unix() {
+		return models.ExportJobResponse{}, false
+	}
+	return *job, true
+}
+
+// CreateExportJob starts a Drive export task for a document or spreadsheet
+func (h *DocHandler) CreateExportJob(c *gin.Context) {
+	docToken := c.Param("doc_token")
+	if docToken == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Doc Token is required"})
+		return
+	}
+
+	var req models.CreateExportJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	docType := req.Type
+	if docType == "" {
+		docType = "docx"
+	}
+	if !isExportFormatSupported(docType, req.FileExtension) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Status:  "error",
+			Message: fmt.Sprintf("Cannot export %s as %s", docType, req.FileExtension),
+		})
+		return
+	}
+	// CSV holds a single sheet, so Drive needs to know which one
+	if req.FileExtension == "csv" && req.SubID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "sub_id (the sheet ID) is required for csv exports"})
+		return
+	}
+
+	taskBuilder := larkdrive.NewExportTaskBuilder().
+		FileExtension(req.FileExtension).
+		Token(docToken).
+		Type(docType)
+
+	if req.SubID != "" {
+		taskBuilder.SubId(req.SubID)
+	}
+
+	input := larkdrive.NewCreateExportTaskReqBuilder().
+		ExportTask(taskBuilder.Build()).
+		Build()
+
+	resp, err := h.Client.Client.Drive.ExportTask.Create(context.Background(), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+	if resp.Data.Ticket == nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: "Export task ticket missing from response"})
+		return
+	}
+
+	job := &models.ExportJobResponse{
+		JobID:         *resp.Data.Ticket,
+		DocToken:      docToken,
+		Type:          docType,
+		FileExtension: req.FileExtension,
+		Status:        ExportStatusProcessing,
+		CreateTime:    time.Now().Unix(),
+	}
+	h.ExportJobs.Put(job)
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   *job,
+	})
+}
+
+// GetExportJob returns the status of an export job, refreshing it from Drive while it is still running
+func (h *DocHandler) GetExportJob(c *gin.Context) {
+	job, ok := h.lookupExportJob(c)
+	if !ok {
+		return
+	}
+
+	if job.Status == ExportStatusProcessing {
+		refreshed, err := h.refreshExportJob(job)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+		job = refreshed
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   job,
+	})
+}
+
+// DownloadExportJob streams the exported file of a finished job to the caller
+func (h *DocHandler) DownloadExportJob(c *gin.Context) {
+	job, ok := h.lookupExportJob(c)
+	if !ok {
+		return
+	}
+
+	if job.Status == ExportStatusProcessing {
+		refreshed, err := h.refreshExportJob(job)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+		job = refreshed
+	}
+
+	switch job.Status {
+	case ExportStatusProcessing:
+		c.JSON(http.StatusConflict, models.APIResponse{Status: "error", Message: "Export job is still processing"})
+		return
+	case ExportStatusFailed:
+		c.JSON(http.StatusConflict, models.APIResponse{Status: "error", Message: fmt.Sprintf("Export job failed: %s", job.ErrorMsg)})
+		return
+	}
+
+	input := larkdrive.NewDownloadExportTaskReqBuilder().
+		FileToken(job.FileToken).
+		Build()
+
+	resp, err := h.Client.Client.Drive.ExportTask.Download(context.Background(), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+
+	fileName := resp.FileName
+	if fileName == "" {
+		fileName = job.FileName
+	}
+	if fileName == "" {
+		fileName = fmt.Sprintf("%s.%s", job.DocToken, job.FileExtension)
+	}
+
+	contentType, ok := exportContentTypes[job.FileExtension]
+	if !ok {
+		contentType = "application/octet-stream"
+	}
+
+	c.DataFromReader(http.StatusOK, -1, contentType, resp.File, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": fileName}),
+	})
+}
+
+// lookupExportJob resolves the job from the URL and writes an error response if it doesn't exist
+func (h *DocHandler) lookupExportJob(c *gin.Context) (models.ExportJobResponse, bool) {
+	docToken := c.Param("doc_token")
+	jobID := c.Param("job_id")
+	if docToken == "" || jobID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Doc Token and Job ID are required"})
+		return models.ExportJobResponse{}, false
+	}
+
+	job, ok := h.ExportJobs.Get(jobID)
+	if !ok || job.DocToken != docToken {
+		c.JSON(http.StatusNotFound, models.APIResponse{Status: "error", Message: "Export job not found"})
+		return models.ExportJobResponse{}, false
+	}
+
+	return job, true
+}
+
+// refreshExportJob queries Drive for the current task result and records it in the job table
+func (h *DocHandler) refreshExportJob(job models.ExportJobResponse) (models.ExportJobResponse, error) {
+	input := larkdrive.NewGetExportTaskReqBuilder().
+		Ticket(job.JobID).
+		Token(job.DocToken).
+		Build()
+
+	resp, err := h.Client.Client.Drive.ExportTask.Get(context.Background(), input)
+	if err != nil {
+		return job, err
+	}
+	if !resp.Success() {
+		return job, fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+	}
+	if resp.Data.Result == nil {
+		return job, nil
+	}
+
+	result := resp.Data.Result
+	if result.JobStatus != nil {
+		job.Status = exportStatus(*result.JobStatus)
+	}
+	if result.FileToken != nil {
+		job.FileToken = *result.FileToken
+	}
+	if result.FileName != nil {
+		job.FileName = *result.FileName
+	}
+	if result.FileSize != nil {
+		job.FileSize = *result.FileSize
+	}
+	if result.JobErrorMsg != nil {
+		job.ErrorMsg = *result.JobErrorMsg
+	}
+
+	h.ExportJobs.Put(&job)
+	return job, nil
+}
+
+// exportStatus maps a Drive job_status code onto the service's job statuses.
+// 0 means success, 1 and 2 mean the task is queued or running, anything else is a failure.
+func exportStatus(jobStatus int) string {
+	switch jobStatus {
+	case 0:
+		return ExportStatusSucceeded
+	case 1, 2:
+		return ExportStatusProcessing
+	default:
+		return ExportStatusFailed
+	}
+}
+
+func isExportFormatSupported(docType, fileExtension string) bool {
+	for _, ext := range exportFormats[docType] {
+		if ext == fileExtension {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+)
+
+// markdownRenderer turns Docx blocks into Markdown
+type markdownRenderer struct {
+	byID map[string]*larkdocx.Block
+}
+
+func newMarkdownRenderer(blocks []*larkdocx.Block) *markdownRenderer {
+	return &markdownRenderer{byID: indexBlocks(blocks)}
+}
+
+// Render renders the given blocks and their descendants, separating top-level blocks with blank lines
+func (r *markdownRenderer) Render(blockIDs []string) string {
+	parts := make([]string, 0, len(blockIDs))
+	for _, id := range blockIDs {
+		if md := r.renderBlock(id, 0); md != "" {
+			parts = append(parts, md)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func (r *markdownRenderer) renderBlock(id string, depth int) string {
+	b, ok := r.byID[id]
+	if !ok {
+		return ""
+	}
+	indent := strings.Repeat("  ", depth)
+
+	if level := headingLevel(b); level > 0 {
+		return strings.Repeat("#", min(level, 6)) + " " + renderInline(blockText(b))
+	}
+
+	switch {
+	case b.Text != nil:
+		return indent + renderInline(b.Text) + r.renderChildren(b, depth)
+	case b.Bullet != nil:
+		return indent + "- " + renderInline(b.Bullet) + r.renderChildren(b, depth+1)
+	case b.Ordered != nil:
+		return indent + "1. " + renderInline(b.Ordered) + r.renderChildren(b, depth+1)
+	case b.Todo != nil:
+		box := "[ ]"
+		if b.Todo.Style != nil && larkcore.BoolValue(b.Todo.Style.Done) {
+			box = "[x]"
+		}
+		return indent + "- " + box + " " + renderInline(b.Todo) + r.renderChildren(b, depth+1)
+	case b.Code != nil:
+		return "```\n" + plainText(b.Code) + "\n```"
+	case b.Quote != nil:
+		return "> " + renderInline(b.Quote)
+	case b.Equation != nil:
+		return "$$\n" + plainText(b.Equation) + "\n$$"
+	case b.Divider != nil:
+		return "---"
+	case b.Image != nil:
+		return fmt.Sprintf("![image](%s)", larkcore.StringValue(b.Image.Token))
+	case b.Table != nil:
+		return r.renderTable(b)
+	}
+
+	// Containers such as callouts, grids and quote containers just render their children
+	var parts []string
+	for _, child := range b.Children {
+		if md := r.renderBlock(child, depth); md != "" {
+			parts = append(parts, md)
+		}
+	}
+	if b.QuoteContainer != nil || b.Callout != nil {
+		for i, p := range parts {
+			parts[i] = "> " + strings.ReplaceAll(p, "\n", "\n> ")
+		}
+		return strings.Join(parts, "\n>\n")
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// renderChildren renders nested blocks (e.g. sub-list items) on the lines following their parent
+func (r *markdownRenderer) renderChildren(b *larkdocx.Block, depth int) string {
+	var sb strings.Builder
+	for _, child := range b.Children {
+		if md := r.renderBlock(child, depth); md != "" {
+			sb.WriteString("\n")
+			sb.WriteString(md)
+		}
+	}
+	return sb.String()
+}
+
+func (r *markdownRenderer) renderTable(b *larkdocx.Block) string {
+	cols := 0
+	if b.Table.Property != nil {
+		cols = larkcore.IntValue(b.Table.Property.ColumnSize)
+	}
+	if cols <= 0 {
+		return ""
+	}
+
+	var rows []string
+	for start := 0; start < len(b.Table.Cells); start += cols {
+		end := min(start+cols, len(b.Table.Cells))
+		cells := make([]string, 0, cols)
+		for _, cellID := range b.Table.Cells[start:end] {
+			cells = append(cells, r.renderCell(cellID))
+		}
+		rows = append(rows, "| "+strings.Join(cells, " | ")+" |")
+		if start == 0 {
+			rows = append(rows, "|"+strings.Repeat(" --- |", cols))
+		}
+	}
+	return strings.Join(rows, "\n")
+}
+
+// renderCell flattens a table cell onto a single line
+func (r *markdownRenderer) renderCell(cellID string) string {
+	cell, ok := r.byID[cellID]
+	if !ok {
+		return ""
+	}
+	var parts []string
+	for _, child := range cell.Children {
+		if md := r.renderBlock(child, 0); md != "" {
+			parts = append(parts, strings.ReplaceAll(md, "\n", " "))
+		}
+	}
+	return strings.ReplaceAll(strings.Join(parts, "<br>"), "|", `\|`)
+}
+
+// renderInline renders text elements with Markdown emphasis, inline code and links
+func renderInline(t *larkdocx.Text) string {
+	if t == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, el := range t.Elements {
+		switch {
+		case el.TextRun != nil:
+			sb.WriteString(styleRun(larkcore.StringValue(el.TextRun.Content), el.TextRun.TextElementStyle))
+		case el.Equation != nil:
+			sb.WriteString("$" + strings.TrimSpace(larkcore.StringValue(el.Equation.Content)) + "$")
+		case el.MentionDoc != nil:
+			link, _ := url.QueryUnescape(larkcore.StringValue(el.MentionDoc.Url))
+			sb.WriteString(fmt.Sprintf("[%s](%s)", larkcore.StringValue(el.MentionDoc.Title), link))
+		case el.MentionUser != nil:
+			sb.WriteString("@" + larkcore.StringValue(el.MentionUser.UserId))
+		}
+	}
+	return sb.String()
+}
+
+func styleRun(content string, style *larkdocx.TextElementStyle) string {
+	if style == nil || strings.TrimSpace(content) == "" {
+		return content
+	}
+	if larkcore.BoolValue(style.InlineCode) {
+		content = "`" + content + "`"
+	}
+	if larkcore.BoolValue(style.Bold) {
+		content = "**" + content + "**"
+	}
+	if larkcore.BoolValue(style.Italic) {
+		content = "*" + content + "*"
+	}
+	if larkcore.BoolValue(style.Strikethrough) {
+		content = "~~" + content + "~~"
+	}
+	if style.Link != nil && style.Link.Url != nil {
+		link, err := url.QueryUnescape(*style.Link.Url)
+		if err != nil {
+			link = *style.Link.Url
+		}
+		content = fmt.Sprintf("[%s](%s)", content, link)
+	}
+	return content
+}
@@ -0,0 +1,450 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"lark-integration-skill/internal/cards"
+	"lark-integration-skill/internal/models"
+	"lark-integration-skill/pkg/larkclient"
+
+	"github.com/gin-gonic/gin"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
+)
+
+type MessageHandler struct {
+	Client *larkclient.ClientWrapper
+	Cards  *cards.Registry
+}
+
+// NewMessageHandler loads the built-in card templates and any in cardTemplateDir
+func NewMessageHandler(client *larkclient.ClientWrapper, cardTemplateDir string) *MessageHandler {
+	registry, err := cards.NewRegistry(cardTemplateDir)
+	if err != nil {
+		log.Printf("Failed to load card templates: %v", err)
+	}
+	return &MessageHandler{Client: client, Cards: registry}
+}
+
+// SendMessage sends a text, post, image, file or card message to a user or chat
+func (h *MessageHandler) SendMessage(c *gin.Context) {
+	var req models.SendMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	msgType, content, err := h.buildMessageContent(req.MessageContent)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	receiveIDType := req.ReceiveIDType
+	if receiveIDType == "" {
+		receiveIDType = guessReceiveIDType(req.ReceiveID)
+	}
+
+	bodyBuilder := larkim.NewCreateMessageReqBodyBuilder().
+		ReceiveId(req.ReceiveID).
+		MsgType(msgType).
+		Content(content)
+
+	if req.UUID != "" {
+		bodyBuilder.Uuid(req.UUID)
+	}
+
+	input := larkim.NewCreateMessageReqBuilder().
+		ReceiveIdType(receiveIDType).
+		Body(bodyBuilder.Build()).
+		Build()
+
+	resp, err := h.Client.Client.Im.Message.Create(context.Background(), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+
+	message := larkim.Message(*resp.Data)
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   toSentMessage(&message),
+	})
+}
+
+// ReplyMessage replies to a message, optionally as a thread
+func (h *MessageHandler) ReplyMessage(c *gin.Context) {
+	messageID := c.Param("message_id")
+	if messageID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Message ID is required"})
+		return
+	}
+
+	var req models.ReplyMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	msgType, content, err := h.buildMessageContent(req.MessageContent)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	bodyBuilder := larkim.NewReplyMessageReqBodyBuilder().
+		MsgType(msgType).
+		Content(content).
+		ReplyInThread(req.ReplyInThread)
+
+	if req.UUID != "" {
+		bodyBuilder.Uuid(req.UUID)
+	}
+
+	input := larkim.NewReplyMessageReqBuilder().
+		MessageId(messageID).
+		Body(bodyBuilder.Build()).
+		Build()
+
+	resp, err := h.Client.Client.Im.Message.Reply(context.Background(), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+
+	message := larkim.Message(*resp.Data)
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   toSentMessage(&message),
+	})
+}
+
+// EditMessage replaces the content of a message the bot sent. Text and post messages are edited in place;
+// cards are updated through the card patch API, which needs the card to have update_multi enabled.
+func (h *MessageHandler) EditMessage(c *gin.Context) {
+	messageID := c.Param("message_id")
+	if messageID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Message ID is required"})
+		return
+	}
+
+	var req models.MessageContent
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	msgType, content, err := h.buildMessageContent(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+
+	switch msgType {
+	case "interactive":
+		input := larkim.NewPatchMessageReqBuilder().
+			MessageId(messageID).
+			Body(larkim.NewPatchMessageReqBodyBuilder().
+				Content(content).
+				Build()).
+			Build()
+
+		resp, err := h.Client.Client.Im.Message.Patch(ctx, input)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+		if !resp.Success() {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Status: "success",
+			Data:   models.SentMessage{MessageID: messageID, MsgType: msgType},
+		})
+
+	case "text", "post":
+		input := larkim.NewUpdateMessageReqBuilder().
+			MessageId(messageID).
+			Body(larkim.NewUpdateMessageReqBodyBuilder().
+				MsgType(msgType).
+				Content(content).
+				Build()).
+			Build()
+
+		resp, err := h.Client.Client.Im.Message.Update(ctx, input)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+		if !resp.Success() {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+			return
+		}
+
+		message := larkim.Message(*resp.Data)
+		c.JSON(http.StatusOK, models.APIResponse{
+			Status: "success",
+			Data:   toSentMessage(&message),
+		})
+
+	default:
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Only text, post and interactive messages can be edited"})
+	}
+}
+
+// RecallMessage recalls (deletes) a message the bot sent
+func (h *MessageHandler) RecallMessage(c *gin.Context) {
+	messageID := c.Param("message_id")
+	if messageID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Message ID is required"})
+		return
+	}
+
+	input := larkim.NewDeleteMessageReqBuilder().
+		MessageId(messageID).
+		Build()
+
+	resp, err := h.Client.Client.Im.Message.Delete(context.Background(), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status:  "success",
+		Message: "Message recalled",
+	})
+}
+
+// UploadMessageImage uploads the multipart "image" field and returns the image_key for image messages
+func (h *MessageHandler) UploadMessageImage(c *gin.Context) {
+	header, err := c.FormFile("image")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Multipart field \"image\" is required"})
+		return
+	}
+	f, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	defer f.Close()
+
+	input := larkim.NewCreateImageReqBuilder().
+		Body(larkim.NewCreateImageReqBodyBuilder().
+			ImageType("message").
+			Image(f).
+			Build()).
+		Build()
+
+	resp, err := h.Client.Client.Im.Image.Create(context.Background(), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   models.UploadImageResponse{ImageKey: larkcore.StringValue(resp.Data.ImageKey)},
+	})
+}
+
+// UploadMessageFile uploads the multipart "file" field and returns the file_key for file messages.
+// The file type is taken from the file_type form field or guessed from the extension.
+func (h *MessageHandler) UploadMessageFile(c *gin.Context) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Multipart field \"file\" is required"})
+		return
+	}
+	f, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	defer f.Close()
+
+	fileType := c.PostForm("file_type")
+	if fileType == "" {
+		fileType = messageFileType(header.Filename)
+	}
+
+	input := larkim.NewCreateFileReqBuilder().
+		Body(larkim.NewCreateFileReqBodyBuilder().
+			FileType(fileType).
+			FileName(header.Filename).
+			File(f).
+			Build()).
+		Build()
+
+	resp, err := h.Client.Client.Im.File.Create(context.Background(), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   models.UploadFileResponse{FileKey: larkcore.StringValue(resp.Data.FileKey)},
+	})
+}
+
+// buildMessageContent returns the msg_type and the JSON-encoded content string the message APIs expect
+func (h *MessageHandler) buildMessageContent(m models.MessageContent) (string, string, error) {
+	msgType := m.MsgType
+	if m.Template != "" {
+		card, err := h.renderCard(m.Template, m.Data)
+		if err != nil {
+			return "", "", err
+		}
+		msgType = "interactive"
+		m.Card = card
+	}
+	if msgType == "" {
+		msgType = "text"
+	}
+
+	var content interface{}
+	switch msgType {
+	case "text":
+		if m.Text == "" {
+			return "", "", errors.New("text is required for text messages")
+		}
+		content = map[string]string{"text": m.Text}
+
+	case "post":
+		switch {
+		case len(m.Post) > 0:
+			// A bare {"title", "content"} is one locale; wrap it
+			if _, ok := m.Post["content"]; ok {
+				content = map[string]interface{}{"zh_cn": m.Post}
+			} else {
+				content = m.Post
+			}
+		case m.Text != "":
+			content = map[string]interface{}{
+				"zh_cn": map[string]interface{}{
+					"title":   m.Title,
+					"content": [][]map[string]string{{{"tag": "md", "text": m.Text}}},
+				},
+			}
+		default:
+			return "", "", errors.New("post or text is required for post messages")
+		}
+
+	case "image":
+		if m.ImageKey == "" {
+			return "", "", errors.New("image_key is required for image messages")
+		}
+		content = map[string]string{"image_key": m.ImageKey}
+
+	case "file":
+		if m.FileKey == "" {
+			return "", "", errors.New("file_key is required for file messages")
+		}
+		content = map[string]string{"file_key": m.FileKey}
+
+	case "interactive":
+		if len(m.Card) == 0 {
+			return "", "", errors.New("card is required for interactive messages")
+		}
+		content = m.Card
+
+	default:
+		return "", "", fmt.Errorf("unsupported msg_type %q", msgType)
+	}
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", "", err
+	}
+	return msgType, string(data), nil
+}
+
+// guessReceiveIDType infers the ID type from its shape: chat IDs start with oc_, open IDs with ou_, union IDs with on_
+func guessReceiveIDType(id string) string {
+	switch {
+	case strings.Contains(id, "@"):
+		return "email"
+	case strings.HasPrefix(id, "oc_"):
+		return "chat_id"
+	case strings.HasPrefix(id, "on_"):
+		return "union_id"
+	case strings.HasPrefix(id, "ou_"):
+		return "open_id"
+	}
+	return "user_id"
+}
+
+// messageFileType maps a file name to the file types the upload API accepts
+func messageFileType(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".opus":
+		return "opus"
+	case ".mp4":
+		return "mp4"
+	case ".pdf":
+		return "pdf"
+	case ".doc", ".docx":
+		return "doc"
+	case ".xls", ".xlsx":
+		return "xls"
+	case ".ppt", ".pptx":
+		return "ppt"
+	}
+	return "stream"
+}
+
+// messageTime converts a message timestamp in Unix milliseconds to RFC 3339
+func messageTime(ms *string) string {
+	n, err := strconv.ParseInt(larkcore.StringValue(ms), 10, 64)
+	if err != nil {
+		return ""
+	}
+	return time.UnixMilli(n).Format(time.RFC3339)
+}
+
+func toSentMessage(m *larkim.Message) models.SentMessage {
+	return models.SentMessage{
+		MessageID:  larkcore.StringValue(m.MessageId),
+		ChatID:     larkcore.StringValue(m.ChatId),
+		RootID:     larkcore.StringValue(m.RootId),
+		ParentID:   larkcore.StringValue(m.ParentId),
+		ThreadID:   larkcore.StringValue(m.ThreadId),
+		MsgType:    larkcore.StringValue(m.MsgType),
+		CreateTime: messageTime(m.CreateTime),
+	}
+}
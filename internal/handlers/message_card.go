@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"lark-integration-skill/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListCardTemplates lists the card templates with the placeholders each one uses
+func (h *MessageHandler) ListCardTemplates(c *gin.Context) {
+	items := []models.CardTemplateInfo{}
+	for _, t := range h.Cards.List() {
+		items = append(items, models.CardTemplateInfo{
+			Name:        t.Name,
+			Description: t.Description,
+			Variables:   t.Variables(),
+			Source:      t.Source,
+		})
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   models.CardTemplateListResponse{Items: items},
+	})
+}
+
+// RenderCardTemplate returns the card JSON a template renders to, without sending it
+func (h *MessageHandler) RenderCardTemplate(c *gin.Context) {
+	var req models.RenderCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	card, err := h.renderCard(c.Param("name"), req.Data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   card,
+	})
+}
+
+func (h *MessageHandler) renderCard(name string, data map[string]interface{}) (map[string]interface{}, error) {
+	t, ok := h.Cards.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown card template %q", name)
+	}
+	card, err := t.Render(data)
+	if err != nil {
+		return nil, fmt.Errorf("card template %q: %v", name, err)
+	}
+	return card, nil
+}
@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"lark-integration-skill/internal/models"
+
+	"github.com/gin-gonic/gin"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
+)
+
+const (
+	// maxThreadMessages caps the messages returned for one thread
+	maxThreadMessages = 1000
+
+	// maxReplyDepth limits how far a reply chain is followed through parent_id
+	maxReplyDepth = 50
+)
+
+// ListChatMessages lists the messages of a chat, optionally between start_time and end_time.
+// Times are RFC 3339, Unix seconds or dates in the timezone query parameter; an end_time date includes that whole day.
+func (h *MessageHandler) ListChatMessages(c *gin.Context) {
+	chatID := c.Param("chat_id")
+	if chatID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Chat ID is required"})
+		return
+	}
+
+	loc, err := time.LoadLocation(c.DefaultQuery("timezone", "UTC"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Invalid timezone: " + err.Error()})
+		return
+	}
+
+	builder := larkim.NewListMessageReqBuilder().
+		ContainerIdType("chat").
+		ContainerId(chatID).
+		PageToken(c.Query("page_token"))
+
+	if s := c.Query("start_time"); s != "" {
+		start, _, err := parseEventTime(s, loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+		builder.StartTime(strconv.FormatInt(start.Unix(), 10))
+	}
+	if s := c.Query("end_time"); s != "" {
+		end, allDay, err := parseEventTime(s, loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+		if allDay {
+			end = end.AddDate(0, 0, 1)
+		}
+		builder.EndTime(strconv.FormatInt(end.Unix(), 10))
+	}
+
+	switch c.DefaultQuery("sort", "asc") {
+	case "asc":
+		builder.SortType(larkim.SortTypeListMessageByCreateTimeAsc)
+	case "desc":
+		builder.SortType(larkim.SortTypeListMessageByCreateTimeDesc)
+	default:
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "sort must be asc or desc"})
+		return
+	}
+
+	var pageSize int
+	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
+		fmt.Sscanf(pageSizeStr, "%d", &pageSize)
+	}
+	if pageSize > 0 {
+		builder.PageSize(min(pageSize, 50))
+	}
+
+	ctx := context.Background()
+	resp, err := h.Client.Client.Im.Message.List(ctx, builder.Build())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data: models.ChatHistoryResponse{
+			Items:     h.toChatMessages(ctx, resp.Data.Items),
+			HasMore:   larkcore.BoolValue(resp.Data.HasMore),
+			PageToken: larkcore.StringValue(resp.Data.PageToken),
+		},
+	})
+}
+
+// GetMessageThread returns the conversation a message belongs to, oldest first.
+// Messages in a thread return the whole thread; other replies return the chain of parents up to the root.
+func (h *MessageHandler) GetMessageThread(c *gin.Context) {
+	messageID := c.Param("message_id")
+	if messageID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Message ID is required"})
+		return
+	}
+
+	ctx := context.Background()
+	message, err := h.getMessage(ctx, messageID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	threadID := larkcore.StringValue(message.ThreadId)
+	if threadID == "" {
+		chain, err := h.replyChain(ctx, message)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, models.APIResponse{
+			Status: "success",
+			Data:   models.MessageThreadResponse{Items: h.toChatMessages(ctx, chain)},
+		})
+		return
+	}
+
+	var messages []*larkim.Message
+	pageToken := ""
+	for len(messages) < maxThreadMessages {
+		input := larkim.NewListMessageReqBuilder().
+			ContainerIdType("thread").
+			ContainerId(threadID).
+			SortType(larkim.SortTypeListMessageByCreateTimeAsc).
+			PageSize(50).
+			PageToken(pageToken).
+			Build()
+
+		resp, err := h.Client.Client.Im.Message.List(ctx, input)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+		if !resp.Success() {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+			return
+		}
+
+		messages = append(messages, resp.Data.Items...)
+		if !larkcore.BoolValue(resp.Data.HasMore) {
+			break
+		}
+		pageToken = larkcore.StringValue(resp.Data.PageToken)
+	}
+	if len(messages) > maxThreadMessages {
+		messages = messages[:maxThreadMessages]
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   models.MessageThreadResponse{ThreadID: threadID, Items: h.toChatMessages(ctx, messages)},
+	})
+}
+
+// DownloadMessageResource streams an image or file attached to a message.
+// The type query parameter is "image" or "file"; when empty it is "image" for img_ keys and "file" otherwise.
+func (h *MessageHandler) DownloadMessageResource(c *gin.Context) {
+	messageID := c.Param("message_id")
+	fileKey := c.Param("file_key")
+	if messageID == "" || fileKey == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Message ID and File Key are required"})
+		return
+	}
+
+	resourceType := c.Query("type")
+	if resourceType == "" {
+		resourceType = "file"
+		if strings.HasPrefix(fileKey, "img_") {
+			resourceType = "image"
+		}
+	}
+	if resourceType != "image" && resourceType != "file" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "type must be image or file"})
+		return
+	}
+
+	input := larkim.NewGetMessageResourceReqBuilder().
+		MessageId(messageID).
+		FileKey(fileKey).
+		Type(resourceType).
+		Build()
+
+	resp, err := h.Client.Client.Im.MessageResource.Get(context.Background(), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+
+	fileName := resp.FileName
+	if fileName == "" {
+		fileName = fileKey
+	}
+
+	contentType := "application/octet-stream"
+	if resp.ApiResp != nil {
+		if ct := resp.Header.Get("Content-Type"); ct != "" {
+			contentType = ct
+		}
+	}
+
+	c.DataFromReader(http.StatusOK, -1, contentType, resp.File, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": fileName}),
+	})
+}
+
+func (h *MessageHandler) getMessage(ctx context.Context, messageID string) (*larkim.Message, error) {
+	input := larkim.NewGetMessageReqBuilder().
+		MessageId(messageID).
+		Build()
+
+	resp, err := h.Client.Client.Im.Message.Get(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success() {
+		return nil, fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+	}
+	// A merged forward lists its children after itself; the first item is the message asked for
+	if len(resp.Data.Items) == 0 {
+		return nil, fmt.Errorf("message %s not found", messageID)
+	}
+	return resp.Data.Items[0], nil
+}
+
+// replyChain follows parent_id from message up to its root and returns the chain oldest first
+func (h *MessageHandler) replyChain(ctx context.Context, message *larkim.Message) ([]*larkim.Message, error) {
+	chain := []*larkim.Message{message}
+	for len(chain) < maxReplyDepth {
+		parentID := larkcore.StringValue(chain[0].ParentId)
+		if parentID == "" {
+			break
+		}
+		parent, err := h.getMessage(ctx, parentID)
+		if err != nil {
+			return nil, err
+		}
+		chain = append([]*larkim.Message{parent}, chain...)
+	}
+	return chain, nil
+}
+
+// toChatMessages normalizes messages to plain text and fills in sender names.
+// Names come from mentions where possible; the rest are looked up, and a failed lookup only leaves names empty.
+func (h *MessageHandler) toChatMessages(ctx context.Context, messages []*larkim.Message) []models.ChatMessage {
+	names := map[string]string{}
+	for _, m := range messages {
+		for _, mention := range m.Mentions {
+			if name := larkcore.StringValue(mention.Name); name != "" {
+				names[larkcore.StringValue(mention.Id)] = name
+			}
+		}
+	}
+
+	var unknown []string
+	seen := map[string]bool{}
+	for _, m := range messages {
+		if m.Sender == nil || larkcore.StringValue(m.Sender.IdType) != "open_id" {
+			continue
+		}
+		id := larkcore.StringValue(m.Sender.Id)
+		if _, ok := names[id]; !ok && id != "" && !seen[id] {
+			seen[id] = true
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 {
+		found, err := lookupUserNames(ctx, h.Client, unknown)
+		if err != nil {
+			log.Printf("Failed to look up message sender names: %v", err)
+		}
+		for id, name := range found {
+			names[id] = name
+		}
+	}
+
+	items := make([]models.ChatMessage, 0, len(messages))
+	for _, m := range messages {
+		items = append(items, toChatMessage(m, names))
+	}
+	return items
+}
+
+func toChatMessage(m *larkim.Message, names map[string]string) models.ChatMessage {
+	msg := models.ChatMessage{
+		MessageID:  larkcore.StringValue(m.MessageId),
+		ChatID:     larkcore.StringValue(m.ChatId),
+		RootID:     larkcore.StringValue(m.RootId),
+		ParentID:   larkcore.StringValue(m.ParentId),
+		ThreadID:   larkcore.StringValue(m.ThreadId),
+		MsgType:    larkcore.StringValue(m.MsgType),
+		CreateTime: messageTime(m.CreateTime),
+		Updated:    larkcore.BoolValue(m.Updated),
+		Deleted:    larkcore.BoolValue(m.Deleted),
+	}
+	if m.Sender != nil {
+		msg.SenderID = larkcore.StringValue(m.Sender.Id)
+		msg.SenderType = larkcore.StringValue(m.Sender.SenderType)
+		msg.SenderName = names[msg.SenderID]
+	}
+
+	if msg.Deleted {
+		msg.Text = "[recalled]"
+		return msg
+	}
+	content := ""
+	if m.Body != nil {
+		content = larkcore.StringValue(m.Body.Content)
+	}
+	msg.Text, msg.Resources = messagePlainText(msg.MsgType, content, m.Mentions)
+	return msg
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"lark-integration-skill/internal/models"
+
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
+)
+
+// cardTextKeys are the card keys read for text, in display order
+var cardTextKeys = []string{"title", "header", "text", "content", "fields", "elements", "columns", "actions"}
+
+// mentionKeyPattern matches whole mention placeholders, so @_user_1 never matches inside @_user_10
+var mentionKeyPattern = regexp.MustCompile(`@_user_\d+`)
+
+// messagePlainText renders received message content as plain text and lists the images and files it carries.
+// Mention placeholders such as @_user_1 become @Name.
+func messagePlainText(msgType, content string, mentions []*larkim.Mention) (string, []models.MessageResource) {
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(content), &body); err != nil {
+		return content, nil
+	}
+
+	var resources []models.MessageResource
+	var text string
+
+	switch msgType {
+	case "text":
+		text, _ = body["text"].(string)
+
+	case "post":
+		text, resources = postPlainText(body)
+
+	case "image":
+		key, _ := body["image_key"].(string)
+		resources = append(resources, models.MessageResource{Type: "image", Key: key})
+		text = "[image]"
+
+	case "file", "audio", "media":
+		key, _ := body["file_key"].(string)
+		name, _ := body["file_name"].(string)
+		resources = append(resources, models.MessageResource{Type: "file", Key: key, Name: name})
+		label := map[string]string{"file": "file", "audio": "audio", "media": "video"}[msgType]
+		if name != "" {
+			text = fmt.Sprintf("[%s: %s]", label, name)
+		} else {
+			text = "[" + label + "]"
+		}
+
+	case "interactive":
+		var parts []string
+		cardText(body, &parts)
+		text = strings.Join(parts, "\n")
+
+	case "share_chat":
+		chatID, _ := body["chat_id"].(string)
+		text = "[shared chat: " + chatID + "]"
+
+	case "share_user":
+		userID, _ := body["user_id"].(string)
+		text = "[shared user: " + userID + "]"
+
+	case "sticker":
+		text = "[sticker]"
+
+	default:
+		text = "[" + msgType + "]"
+	}
+
+	return replaceMentions(text, mentions), resources
+}
+
+// replaceMentions turns mention placeholders into @Name, leaving unknown ones as they are
+func replaceMentions(text string, mentions []*larkim.Mention) string {
+	if len(mentions) == 0 {
+		return text
+	}
+	names := make(map[string]string, len(mentions))
+	for _, m := range mentions {
+		names[larkcore.StringValue(m.Key)] = larkcore.StringValue(m.Name)
+	}
+	return mentionKeyPattern.ReplaceAllStringFunc(text, func(key string) string {
+		if name, ok := names[key]; ok {
+			return "@" + name
+		}
+		return key
+	})
+}
+
+// postPlainText flattens a rich text post into lines, one per paragraph, with the title first.
+// Received posts hold title and content at the top level; sent ones may still be wrapped in a locale.
+func postPlainText(body map[string]interface{}) (string, []models.MessageResource) {
+	if _, ok := body["content"]; !ok {
+		for _, locale := range []string{"zh_cn", "en_us", "ja_jp"} {
+			if inner, ok := body[locale].(map[string]interface{}); ok {
+				body = inner
+				break
+			}
+		}
+	}
+
+	var lines []string
+	var resources []models.MessageResource
+	if title, _ := body["title"].(string); title != "" {
+		lines = append(lines, title)
+	}
+
+	paragraphs, _ := body["content"].([]interface{})
+	for _, p := range paragraphs {
+		nodes, _ := p.([]interface{})
+		var line strings.Builder
+		for _, n := range nodes {
+			node, _ := n.(map[string]interface{})
+			text, _ := node["text"].(string)
+
+			switch node["tag"] {
+			case "text", "md", "code_block":
+				line.WriteString(text)
+			case "a":
+				href, _ := node["href"].(string)
+				if text == "" || text == href {
+					line.WriteString(href)
+				} else {
+					fmt.Fprintf(&line, "%s (%s)", text, href)
+				}
+			case "at":
+				name, _ := node["user_name"].(string)
+				if name == "" {
+					// Left as the mention key, replaced with the name later
+					name, _ = node["user_id"].(string)
+					line.WriteString(name)
+				} else {
+					line.WriteString("@" + name)
+				}
+			case "img":
+				key, _ := node["image_key"].(string)
+				resources = append(resources, models.MessageResource{Type: "image", Key: key})
+				line.WriteString("[image]")
+			case "media":
+				key, _ := node["file_key"].(string)
+				resources = append(resources, models.MessageResource{Type: "file", Key: key})
+				line.WriteString("[video]")
+			case "emotion":
+				emoji, _ := node["emoji_type"].(string)
+				line.WriteString(":" + emoji + ":")
+			case "hr":
+				line.WriteString("---")
+			}
+		}
+		lines = append(lines, line.String())
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n")), resources
+}
+
+// cardText collects the visible strings of a card in display order
+func cardText(v interface{}, parts *[]string) {
+	switch v := v.(type) {
+	case string:
+		if s := strings.TrimSpace(v); s != "" {
+			*parts = append(*parts, s)
+		}
+	case []interface{}:
+		for _, item := range v {
+			cardText(item, parts)
+		}
+	case map[string]interface{}:
+		for _, key := range cardTextKeys {
+			if item, ok := v[key]; ok {
+				cardText(item, parts)
+			}
+		}
+	}
+}
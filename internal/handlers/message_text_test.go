@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"lark-integration-skill/internal/models"
+
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
+)
+
+func testMention(key, name string) *larkim.Mention {
+	return &larkim.Mention{Key: larkcore.StringPtr(key), Name: larkcore.StringPtr(name)}
+}
+
+func TestMessagePlainText(t *testing.T) {
+	mentions := []*larkim.Mention{testMention("@_user_1", "Ann")}
+
+	tests := []struct {
+		name      string
+		msgType   string
+		content   string
+		want      string
+		resources []models.MessageResource
+	}{
+		{"text with mention", "text", `{"text":"hi @_user_1, see above"}`, "hi @Ann, see above", nil},
+		{"image", "image", `{"image_key":"img_1"}`, "[image]", []models.MessageResource{{Type: "image", Key: "img_1"}}},
+		{"file", "file", `{"file_key":"file_1","file_name":"a.pdf"}`, "[file: a.pdf]", []models.MessageResource{{Type: "file", Key: "file_1", Name: "a.pdf"}}},
+		{"audio", "audio", `{"file_key":"file_2","duration":3000}`, "[audio]", []models.MessageResource{{Type: "file", Key: "file_2"}}},
+		{"video", "media", `{"file_key":"file_3","image_key":"img_3","file_name":"clip.mp4"}`, "[video: clip.mp4]", []models.MessageResource{{Type: "file", Key: "file_3", Name: "clip.mp4"}}},
+		{"card", "interactive", `{"title":"Deploy","elements":[[{"tag":"text","text":"done"}],[{"tag":"button","text":"Open"}]]}`, "Deploy\ndone\nOpen", nil},
+		{"shared chat", "share_chat", `{"chat_id":"oc_1"}`, "[shared chat: oc_1]", nil},
+		{"sticker", "sticker", `{"file_key":"s"}`, "[sticker]", nil},
+		{"other", "system", `{}`, "[system]", nil},
+		{"not JSON", "text", `plain`, "plain", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, resources := messagePlainText(tt.msgType, tt.content, mentions)
+			if text != tt.want {
+				t.Errorf("text = %q, want %q", text, tt.want)
+			}
+			if !reflect.DeepEqual(resources, tt.resources) {
+				t.Errorf("resources = %#v, want %#v", resources, tt.resources)
+			}
+		})
+	}
+}
+
+func TestReplaceMentions(t *testing.T) {
+	var mentions []*larkim.Mention
+	for i := 1; i <= 11; i++ {
+		mentions = append(mentions, testMention(fmt.Sprintf("@_user_%d", i), fmt.Sprintf("User%d", i)))
+	}
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"@_user_1 and @_user_10", "@User1 and @User10"},
+		{"@_user_11!", "@User11!"},
+		{"@_user_12 is unknown", "@_user_12 is unknown"},
+		{"@_all please", "@_all please"},
+	}
+	for _, tt := range tests {
+		if got := replaceMentions(tt.in, mentions); got != tt.want {
+			t.Errorf("replaceMentions(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPostPlainText(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      map[string]interface{}
+		want      string
+		resources []models.MessageResource
+	}{
+		{
+			name: "received post",
+			body: map[string]interface{}{
+				"title": "Notes",
+				"content": []interface{}{
+					[]interface{}{
+						map[string]interface{}{"tag": "at", "user_id": "@_user_1"},
+						map[string]interface{}{"tag": "text", "text": " see "},
+						map[string]interface{}{"tag": "a", "text": "the doc", "href": "https://example.com"},
+					},
+					[]interface{}{
+						map[string]interface{}{"tag": "img", "image_key": "img_1"},
+						map[string]interface{}{"tag": "emotion", "emoji_type": "SMILE"},
+					},
+				},
+			},
+			want:      "Notes\n@_user_1 see the doc (https://example.com)\n[image]:SMILE:",
+			resources: []models.MessageResource{{Type: "image", Key: "img_1"}},
+		},
+		{
+			name: "post wrapped in a locale",
+			body: map[string]interface{}{
+				"en_us": map[string]interface{}{
+					"title": "",
+					"content": []interface{}{
+						[]interface{}{map[string]interface{}{"tag": "at", "user_name": "Bob"}, map[string]interface{}{"tag": "text", "text": " hi"}},
+						[]interface{}{map[string]interface{}{"tag": "hr"}},
+						[]interface{}{map[string]interface{}{"tag": "a", "href": "https://example.com", "text": "https://example.com"}},
+					},
+				},
+			},
+			want: "@Bob hi\n---\nhttps://example.com",
+		},
+		{
+			name: "video",
+			body: map[string]interface{}{
+				"content": []interface{}{
+					[]interface{}{map[string]interface{}{"tag": "media", "file_key": "file_1", "image_key": "img_1"}},
+				},
+			},
+			want:      "[video]",
+			resources: []models.MessageResource{{Type: "file", Key: "file_1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, resources := postPlainText(tt.body)
+			if text != tt.want {
+				t.Errorf("text = %q, want %q", text, tt.want)
+			}
+			if !reflect.DeepEqual(resources, tt.resources) {
+				t.Errorf("resources = %#v, want %#v", resources, tt.resources)
+			}
+		})
+	}
+}
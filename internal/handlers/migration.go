@@ -0,0 +1,559 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"lark-integration-skill/internal/models"
+	"lark-integration-skill/pkg/larkclient"
+
+	"github.com/gin-gonic/gin"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkdrive "github.com/larksuite/oapi-sdk-go/v3/service/drive/v1"
+)
+
+// Migration job statuses
+const (
+	MigrationRunning   = "running"
+	MigrationCompleted = "completed"
+	MigrationFailed    = "failed"
+)
+
+// Migration item statuses
+const (
+	ItemPending         = "pending"
+	ItemProcessing      = "processing"
+	ItemMoved           = "moved"
+	ItemApprovalPending = "approval_pending"
+	ItemFailed          = "failed"
+	ItemSkipped         = "skipped"
+)
+
+// movableDriveTypes are the Drive file types MoveDocsToWiki accepts
+var movableDriveTypes = map[string]bool{
+	"doc":      true,
+	"docx":     true,
+	"sheet":    true,
+	"bitable":  true,
+	"mindnote": true,
+	"file":     true,
+}
+
+// MigrationHandler migrates Drive folders into wiki spaces as background jobs
+type MigrationHandler struct {
+	Client *larkclient.ClientWrapper
+	Wiki   *WikiHandler
+	Jobs   *MigrationStore
+}
+
+// NewMigrationHandler loads persisted jobs from dataDir and resumes any that were still running.
+// wiki is the service's configured wiki handler, used for node creation and moves.
+func NewMigrationHandler(client *larkclient.ClientWrapper, wiki *WikiHandler, dataDir string) *MigrationHandler {
+	store, err := NewMigrationStore(filepath.Join(dataDir, "migrations"))
+	if err != nil {
+		log.Printf("Failed to load migration jobs: %v", err)
+	}
+
+	h := &MigrationHandler{
+		Client: client,
+		Wiki:   wiki,
+		Jobs:   store,
+	}
+
+	for _, job := range store.List() {
+		if job.Status == MigrationRunning && store.TryStart(job.JobID) {
+			full, _ := store.Get(job.JobID)
+			log.Printf("Resuming migration %s", job.JobID)
+			go h.run(full)
+		}
+	}
+
+	return h
+}
+
+// StartMigration starts moving every document and subfolder of a Drive folder into a wiki space
+func (h *MigrationHandler) StartMigration(c *gin.Context) {
+	spaceID := c.Param("space_id")
+	if spaceID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Space ID is required"})
+		return
+	}
+
+	var req models.CreateMigrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	jobID, err := newJobID()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	job := models.MigrationJob{
+		JobID:           jobID,
+		SpaceID:         spaceID,
+		FolderToken:     req.FolderToken,
+		ParentWikiToken: req.ParentWikiToken,
+		Apply:           req.Apply,
+		Status:          MigrationRunning,
+		Folders: []models.MigrationFolder{
+			{FolderToken: req.FolderToken, WikiToken: req.ParentWikiToken},
+		},
+		Items:      []models.MigrationItem{},
+		CreateTime: time.Now().Unix(),
+	}
+
+	if err := h.Jobs.Save(&job); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	h.Jobs.TryStart(job.JobID)
+
+	// Respond before starting the run, which shares the folder and item slices with job
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   job,
+	})
+	go h.run(job)
+}
+
+// GetMigration returns a migration job with per-folder and per-item progress
+func (h *MigrationHandler) GetMigration(c *gin.Context) {
+	jobID := c.Param("job_id")
+	job, ok := h.Jobs.Get(jobID)
+	if !ok {
+		c.JSON(http.StatusNotFound, models.APIResponse{Status: "error", Message: "Migration job not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   job,
+	})
+}
+
+// ListMigrations lists migration jobs, most recent first
+func (h *MigrationHandler) ListMigrations(c *gin.Context) {
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   models.MigrationJobListResponse{Items: h.Jobs.List()},
+	})
+}
+
+// ResumeMigration restarts a stopped job. Failed items are retried and async moves are polled again.
+func (h *MigrationHandler) ResumeMigration(c *gin.Context) {
+	jobID := c.Param("job_id")
+	job, ok := h.Jobs.Get(jobID)
+	if !ok {
+		c.JSON(http.StatusNotFound, models.APIResponse{Status: "error", Message: "Migration job not found"})
+		return
+	}
+	if !h.Jobs.TryStart(jobID) {
+		c.JSON(http.StatusConflict, models.APIResponse{Status: "error", Message: "Migration job is already running"})
+		return
+	}
+
+	for i := range job.Items {
+		if job.Items[i].Status == ItemFailed {
+			job.Items[i].Status = ItemPending
+			job.Items[i].Error = ""
+		}
+	}
+	job.Status = MigrationRunning
+	job.Error = ""
+
+	if err := h.Jobs.Save(&job); err != nil {
+		h.Jobs.Finish(jobID)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	// Respond before starting the run, which shares the folder and item slices with job
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   job,
+	})
+	go h.run(job)
+}
+
+func (h *MigrationHandler) run(job models.MigrationJob) {
+	defer h.Jobs.Finish(job.JobID)
+
+	if err := h.migrate(context.Background(), &job); err != nil {
+		job.Status = MigrationFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = MigrationCompleted
+	}
+
+	if err := h.Jobs.Save(&job); err != nil {
+		log.Printf("Failed to save migration %s: %v", job.JobID, err)
+	}
+}
+
+// migrate walks the folder tree breadth first, creating a wiki node per subfolder, then moves each item.
+// Progress is saved after every step so a restarted job picks up where it stopped.
+func (h *MigrationHandler) migrate(ctx context.Context, job *models.MigrationJob) error {
+	for i := 0; i < len(job.Folders); i++ {
+		if job.Folders[i].Listed {
+			continue
+		}
+
+		// Subfolders become wiki nodes; the root folder maps to the target parent
+		if i > 0 && job.Folders[i].WikiToken == "" {
+			parentWiki := folderWikiToken(job, job.Folders[i].ParentToken)
+			wikiToken, err := h.ensureWikiNode(ctx, job.SpaceID, parentWiki, job.Folders[i].Name)
+			if err != nil {
+				return fmt.Errorf("create node for folder %q: %w", job.Folders[i].Name, err)
+			}
+			job.Folders[i].WikiToken = wikiToken
+			if err := h.Jobs.Save(job); err != nil {
+				return err
+			}
+		}
+
+		files, err := h.listFolder(ctx, job.Folders[i].FolderToken)
+		if err != nil {
+			return fmt.Errorf("list folder %s: %w", job.Folders[i].FolderToken, err)
+		}
+
+		for _, f := range files {
+			fileType := larkcore.StringValue(f.Type)
+			if fileType == "folder" {
+				job.Folders = append(job.Folders, models.MigrationFolder{
+					FolderToken: larkcore.StringValue(f.Token),
+					Name:        larkcore.StringValue(f.Name),
+					ParentToken: job.Folders[i].FolderToken,
+				})
+				continue
+			}
+
+			item := models.MigrationItem{
+				ObjToken:    larkcore.StringValue(f.Token),
+				ObjType:     fileType,
+				Name:        larkcore.StringValue(f.Name),
+				FolderToken: job.Folders[i].FolderToken,
+				Status:      ItemPending,
+			}
+			if !movableDriveTypes[fileType] {
+				item.Status = ItemSkipped
+				item.Error = fmt.Sprintf("%s files can't be moved to wiki", fileType)
+			}
+			job.Items = append(job.Items, item)
+		}
+
+		job.Folders[i].Listed = true
+		if err := h.Jobs.Save(job); err != nil {
+			return err
+		}
+	}
+
+	// Start every pending move first, then poll the async ones together
+	for i := range job.Items {
+		item := &job.Items[i]
+		if item.Status != ItemPending {
+			continue
+		}
+		h.moveItem(ctx, job, item)
+		if err := h.Jobs.Save(job); err != nil {
+			return err
+		}
+	}
+
+	return h.pollItems(ctx, job)
+}
+
+func (h *MigrationHandler) moveItem(ctx context.Context, job *models.MigrationJob, item *models.MigrationItem) {
+	parentWiki := folderWikiToken(job, item.FolderToken)
+	res, err := h.Wiki.moveDocToWiki(ctx, job.SpaceID, parentWiki, item.ObjType, item.ObjToken, job.Apply)
+	if err != nil {
+		item.Status = ItemFailed
+		item.Error = err.Error()
+		return
+	}
+
+	item.WikiToken = res.WikiToken
+	item.TaskID = res.TaskID
+	switch res.Status {
+	case MoveStatusMoved:
+		item.Status = ItemMoved
+	case MoveStatusApprovalPending:
+		item.Status = ItemApprovalPending
+	case MoveStatusProcessing:
+		item.Status = ItemProcessing
+	default:
+		item.Status = ItemFailed
+		item.Error = "move returned neither a wiki token nor a task"
+	}
+}
+
+// pollItems polls every processing item's move task in rounds until all have finished or
+// maxWikiTaskWait passes. Items still processing then are polled again on resume.
+func (h *MigrationHandler) pollItems(ctx context.Context, job *models.MigrationJob) error {
+	deadline := time.Now().Add(maxWikiTaskWait)
+
+	for {
+		pending, changed := 0, false
+		for i := range job.Items {
+			item := &job.Items[i]
+			if item.Status != ItemProcessing {
+				continue
+			}
+
+			task, err := h.Wiki.getWikiMoveTask(ctx, item.TaskID)
+			if err != nil {
+				item.Error = err.Error()
+				pending++
+				continue
+			}
+			if applyMoveTask(item, task) {
+				changed = true
+			} else {
+				pending++
+			}
+		}
+
+		if changed {
+			if err := h.Jobs.Save(job); err != nil {
+				return err
+			}
+		}
+		if pending == 0 || time.Now().Add(wikiTaskPollEvery).After(deadline) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wikiTaskPollEvery):
+		}
+	}
+}
+
+// applyMoveTask records a finished move task on its item, returning false while the task is still running
+func applyMoveTask(item *models.MigrationItem, task models.WikiTaskResponse) bool {
+	switch task.Status {
+	case TaskStatusSucceeded:
+		item.Status = ItemMoved
+		item.Error = ""
+		for _, r := range task.Results {
+			if r.NodeToken != "" {
+				item.WikiToken = r.NodeToken
+				break
+			}
+		}
+		return true
+	case TaskStatusFailed:
+		item.Status = ItemFailed
+		var msgs []string
+		for _, r := range task.Results {
+			if r.StatusMsg != "" {
+				msgs = append(msgs, r.StatusMsg)
+			}
+		}
+		item.Error = strings.Join(msgs, "; ")
+		return true
+	}
+	return false
+}
+
+// ensureWikiNode returns the child of parentToken titled title, creating it if needed.
+// Reusing an existing node keeps a resumed job from creating duplicates.
+func (h *MigrationHandler) ensureWikiNode(ctx context.Context, spaceID, parentToken, title string) (string, error) {
+	children, err := h.Wiki.listAllChildNodes(ctx, spaceID, parentToken)
+	if err != nil {
+		return "", err
+	}
+	if node := findNodeByTitle(children, title); node != nil {
+		return larkcore.StringValue(node.NodeToken), nil
+	}
+
+	node, err := h.Wiki.createWikiNode(ctx, spaceID, parentToken, title, "docx")
+	if err != nil {
+		return "", err
+	}
+	return larkcore.StringValue(node.NodeToken), nil
+}
+
+// listFolder pages through the files of a Drive folder
+func (h *MigrationHandler) listFolder(ctx context.Context, folderToken string) ([]*larkdrive.File, error) {
+	var files []*larkdrive.File
+	pageToken := ""
+
+	for {
+		builder := larkdrive.NewListFileReqBuilder().
+			FolderToken(folderToken).
+			PageSize(200)
+
+		if pageToken != "" {
+			builder.PageToken(pageToken)
+		}
+
+		resp, err := h.Client.Client.Drive.File.List(ctx, builder.Build())
+		if err != nil {
+			return nil, err
+		}
+		if !resp.Success() {
+			return nil, fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+		}
+
+		files = append(files, resp.Data.Files...)
+
+		if !larkcore.BoolValue(resp.Data.HasMore) || larkcore.StringValue(resp.Data.NextPageToken) == "" {
+			return files, nil
+		}
+		pageToken = *resp.Data.NextPageToken
+	}
+}
+
+func folderWikiToken(job *models.MigrationJob, folderToken string) string {
+	for _, f := range job.Folders {
+		if f.FolderToken == folderToken {
+			return f.WikiToken
+		}
+	}
+	return job.ParentWikiToken
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate job ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// MigrationStore persists migration jobs as JSON files, one per job
+type MigrationStore struct {
+	dir     string
+	mu      sync.Mutex
+	jobs    map[string][]byte
+	running map[string]bool
+}
+
+// NewMigrationStore loads the jobs saved in dir. The returned store is usable even if loading fails.
+func NewMigrationStore(dir string) (*MigrationStore, error) {
+	s := &MigrationStore{
+		dir:     dir,
+		jobs:    make(map[string][]byte),
+		running: make(map[string]bool),
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return s, err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return s, err
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return s, err
+		}
+		s.jobs[strings.TrimSuffix(filepath.Base(path), ".json")] = data
+	}
+
+	return s, nil
+}
+
+// Save updates the job's counts and timestamp and writes it to disk
+func (s *MigrationStore) Save(job *models.MigrationJob) error {
+	job.UpdateTime = time.Now().Unix()
+	job.Counts = make(map[string]int)
+	for _, item := range job.Items {
+		job.Counts[item.Status]++
+	}
+
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Write to a temp file and rename so a crash never leaves a truncated job file
+	path := filepath.Join(s.dir, job.JobID+".json")
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+
+	s.jobs[job.JobID] = data
+	return nil
+}
+
+func (s *MigrationStore) Get(jobID string) (models.MigrationJob, bool) {
+	s.mu.Lock()
+	data, ok := s.jobs[jobID]
+	s.mu.Unlock()
+	if !ok {
+		return models.MigrationJob{}, false
+	}
+
+	var job models.MigrationJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return models.MigrationJob{}, false
+	}
+	return job, true
+}
+
+// List returns job summaries without folders and items, most recent first
+func (s *MigrationStore) List() []models.MigrationJob {
+	s.mu.Lock()
+	ids := make([]string, 0, len(s.jobs))
+	for id := range s.jobs {
+		ids = append(ids, id)
+	}
+	s.mu.Unlock()
+
+	jobs := make([]models.MigrationJob, 0, len(ids))
+	for _, id := range ids {
+		job, ok := s.Get(id)
+		if !ok {
+			continue
+		}
+		job.Folders = nil
+		job.Items = nil
+		jobs = append(jobs, job)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreateTime > jobs[j].CreateTime })
+	return jobs
+}
+
+// TryStart marks a job as running in this process, returning false if it already is
+func (s *MigrationStore) TryStart(jobID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running[jobID] {
+		return false
+	}
+	s.running[jobID] = true
+	return true
+}
+
+func (s *MigrationStore) Finish(jobID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.running, jobID)
+}
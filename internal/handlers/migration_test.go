@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"testing"
+
+	"lark-integration-skill/internal/models"
+)
+
+func TestApplyMoveTask(t *testing.T) {
+	tests := []struct {
+		name      string
+		task      models.WikiTaskResponse
+		done      bool
+		status    string
+		wikiToken string
+		errMsg    string
+	}{
+		{
+			name:   "still moving",
+			task:   models.WikiTaskResponse{Status: TaskStatusProcessing, Results: []models.WikiMoveResult{{StatusCode: -1}}},
+			status: ItemProcessing,
+			errMsg: "timeout",
+		},
+		{
+			name:      "moved",
+			task:      models.WikiTaskResponse{Status: TaskStatusSucceeded, Results: []models.WikiMoveResult{{NodeToken: "wik_1"}}},
+			done:      true,
+			status:    ItemMoved,
+			wikiToken: "wik_1",
+		},
+		{
+			name:   "failed",
+			task:   models.WikiTaskResponse{Status: TaskStatusFailed, Results: []models.WikiMoveResult{{StatusMsg: "no permission"}, {StatusMsg: "locked"}}},
+			done:   true,
+			status: ItemFailed,
+			errMsg: "no permission; locked",
+		},
+	}
+	for _, tt := range tests {
+		item := models.MigrationItem{Status: ItemProcessing, Error: "timeout"}
+		if done := applyMoveTask(&item, tt.task); done != tt.done {
+			t.Errorf("%s: applyMoveTask = %v, want %v", tt.name, done, tt.done)
+		}
+		if item.Status != tt.status || item.WikiToken != tt.wikiToken || item.Error != tt.errMsg {
+			t.Errorf("%s: item = %+v, want status %q, wiki token %q, error %q", tt.name, item, tt.status, tt.wikiToken, tt.errMsg)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"lark-integration-skill/internal/models"
+
+	"github.com/gin-gonic/gin"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+)
+
+const (
+	blockTypeText    = 2
+	blockTypeCallout = 19
+	blockTypeImage   = 27
+
+	defaultTOCTitle    = "Table of Contents"
+	defaultTOCMaxLevel = 3
+)
+
+// GetDocumentOutline returns the heading hierarchy of a Docx file
+func (h *DocHandler) GetDocumentOutline(c *gin.Context) {
+	docToken := c.Param("doc_token")
+	if docToken == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Doc Token is required"})
+		return
+	}
+
+	maxLevel := 9
+	if maxLevelStr := c.Query("max_level"); maxLevelStr != "" {
+		level, err := strconv.Atoi(maxLevelStr)
+		if err != nil || level < 1 {
+			c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "max_level must be a positive integer"})
+			return
+		}
+		maxLevel = level
+	}
+
+	blocks, err := h.fetchAllBlocks(context.Background(), docToken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data: models.DocOutlineResponse{
+			DocToken: docToken,
+			Items:    buildOutline(docToken, documentOrder(blocks, docToken), maxLevel),
+		},
+	})
+}
+
+// RefreshDocumentTOC inserts a table of contents callout at the top of the document,
+// replacing the one from a previous call if it is still the first block
+func (h *DocHandler) RefreshDocumentTOC(c *gin.Context) {
+	docToken := c.Param("doc_token")
+	if docToken == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Doc Token is required"})
+		return
+	}
+
+	var req models.RefreshTOCRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+	}
+
+	title := req.Title
+	if title == "" {
+		title = defaultTOCTitle
+	}
+	maxLevel := req.MaxLevel
+	if maxLevel <= 0 {
+		maxLevel = defaultTOCMaxLevel
+	}
+
+	ctx := context.Background()
+	blocks, err := h.fetchAllBlocks(ctx, docToken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	byID := indexBlocks(blocks)
+	root, ok := byID[docToken]
+	if !ok {
+		c.JSON(http.StatusNotFound, models.APIResponse{Status: "error", Message: "Document root block not found"})
+		return
+	}
+
+	// The callout, its title and one line per heading go into a single descendant create call
+	headings := flattenOutline(buildOutline(docToken, documentOrder(blocks, docToken), maxLevel))
+	if len(headings)+2 > maxDescendantsPerRequest {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Status:  "error",
+			Message: fmt.Sprintf("The TOC would list %d headings, more than the %d Lark allows in one call; lower max_level", len(headings), maxDescendantsPerRequest-2),
+		})
+		return
+	}
+
+	// Drop an existing TOC first, snapshotting it like any other delete
+	replaced := false
+	if len(root.Children) > 0 && isTOCBlock(byID, root.Children[0], title) {
+		existing := root.Children[0]
+		snapshot := collectSubtree(byID, []string{existing})
+		if err := h.deleteChildRange(ctx, docToken, docToken, 0, 1); err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: fmt.Sprintf("Failed to remove existing TOC: %v", err)})
+			return
+		}
+		h.Snapshots.Record(&docOperation{
+			Kind:       OperationDelete,
+			DocumentID: docToken,
+			BlockID:    docToken,
+			Index:      0,
+			BlockIDs:   []string{existing},
+			Blocks:     snapshot,
+		})
+		replaced = true
+	}
+
+	tocID, err := h.insertTOC(ctx, docToken, title, headings)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: fmt.Sprintf("Failed to insert TOC: %v", err)})
+		return
+	}
+
+	h.Snapshots.Record(&docOperation{
+		Kind:       OperationCreate,
+		DocumentID: docToken,
+		BlockID:    docToken,
+		Index:      0,
+		BlockIDs:   []string{tocID},
+	})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data: models.RefreshTOCResponse{
+			BlockID:  tocID,
+			Replaced: replaced,
+			Headings: len(headings),
+		},
+	})
+}
+
+// buildOutline nests headings under the closest preceding heading of a higher level
+func buildOutline(docToken string, ordered []*larkdocx.Block, maxLevel int) []*models.OutlineItem {
+	var roots []*models.OutlineItem
+	var stack []*models.OutlineItem
+
+	for _, b := range ordered {
+		level := headingLevel(b)
+		if level == 0 || level > maxLevel {
+			continue
+		}
+
+		blockID := larkcore.StringValue(b.BlockId)
+		item := &models.OutlineItem{
+			Level:   level,
+			Text:    strings.TrimSpace(plainText(blockText(b))),
+			BlockID: blockID,
+			Anchor:  fmt.Sprintf("https://open.larksuite.com/docx/%s#%s", docToken, blockID),
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].Level >= level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, item)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, item)
+		}
+		stack = append(stack, item)
+	}
+
+	return roots
+}
+
+func flattenOutline(items []*models.OutlineItem) []*models.OutlineItem {
+	var out []*models.OutlineItem
+	for _, item := range items {
+		out = append(out, item)
+		out = append(out, flattenOutline(item.Children)...)
+	}
+	return out
+}
+
+// isTOCBlock reports whether blockID is a callout whose first line is the TOC title
+func isTOCBlock(byID map[string]*larkdocx.Block, blockID, title string) bool {
+	b, ok := byID[blockID]
+	if !ok || b.Callout == nil || len(b.Children) == 0 {
+		return false
+	}
+	first, ok := byID[b.Children[0]]
+	if !ok {
+		return false
+	}
+	return strings.TrimSpace(plainText(blockText(first))) == title
+}
+
+// insertTOC creates the TOC callout as the first block of the document and returns its block ID
+func (h *DocHandler) insertTOC(ctx context.Context, docToken, title string, headings []*models.OutlineItem) (string, error) {
+	const calloutID = "toc_callout"
+
+	titleID := "toc_title"
+	childIDs := []string{titleID}
+	descendants := []*larkdocx.Block{
+		larkdocx.NewBlockBuilder().
+			BlockId(titleID).
+			BlockType(blockTypeText).
+			Text(larkdocx.NewTextBuilder().
+				Elements([]*larkdocx.TextElement{
+					larkdocx.NewTextElementBuilder().
+						TextRun(larkdocx.NewTextRunBuilder().
+							Content(title).
+							TextElementStyle(larkdocx.NewTextElementStyleBuilder().Bold(true).Build()).
+							Build()).
+						Build(),
+				}).
+				Build()).
+			Build(),
+	}
+
+	for i, item := range headings {
+		itemID := fmt.Sprintf("toc_item_%d", i)
+		childIDs = append(childIDs, itemID)
+		descendants = append(descendants, larkdocx.NewBlockBuilder().
+			BlockId(itemID).
+			BlockType(blockTypeText).
+			Text(larkdocx.NewTextBuilder().
+				Elements([]*larkdocx.TextElement{
+					larkdocx.NewTextElementBuilder().
+						TextRun(larkdocx.NewTextRunBuilder().
+							Content(strings.Repeat("    ", item.Level-1)).
+							Build()).
+						Build(),
+					larkdocx.NewTextElementBuilder().
+						TextRun(larkdocx.NewTextRunBuilder().
+							Content(item.Text).
+							TextElementStyle(larkdocx.NewTextElementStyleBuilder().
+								Link(larkdocx.NewLinkBuilder().Url(url.QueryEscape(item.Anchor)).Build()).
+								Build()).
+							Build()).
+						Build(),
+				}).
+				Build()).
+			Build())
+	}
+
+	callout := larkdocx.NewBlockBuilder().
+		BlockId(calloutID).
+		BlockType(blockTypeCallout).
+		Callout(larkdocx.NewCalloutBuilder().EmojiId("bookmark_tabs").Build()).
+		Children(childIDs).
+		Build()
+
+	input := larkdocx.NewCreateDocumentBlockDescendantReqBuilder().
+		DocumentId(docToken).
+		BlockId(docToken).
+		Body(larkdocx.NewCreateDocumentBlockDescendantReqBodyBuilder().
+			ChildrenId([]string{calloutID}).
+			Index(0).
+			Descendants(append([]*larkdocx.Block{callout}, descendants...)).
+			Build()).
+		Build()
+
+	resp, err := h.Client.Client.Docx.DocumentBlockDescendant.Create(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	if !resp.Success() {
+		return "", fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+	}
+
+	for _, rel := range resp.Data.BlockIdRelations {
+		if larkcore.StringValue(rel.TemporaryBlockId) == calloutID {
+			return larkcore.StringValue(rel.BlockId), nil
+		}
+	}
+	if len(resp.Data.Children) > 0 {
+		return larkcore.StringValue(resp.Data.Children[0].BlockId), nil
+	}
+	return "", fmt.Errorf("TOC block ID missing from response")
+}
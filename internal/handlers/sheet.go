@@ -0,0 +1,402 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"lark-integration-skill/internal/models"
+	"lark-integration-skill/pkg/larkclient"
+
+	"github.com/gin-gonic/gin"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larksheets "github.com/larksuite/oapi-sdk-go/v3/service/sheets/v3"
+)
+
+type SheetHandler struct {
+	Client *larkclient.ClientWrapper
+}
+
+func NewSheetHandler(client *larkclient.ClientWrapper) *SheetHandler {
+	return &SheetHandler{Client: client}
+}
+
+// ListSheets lists the sheets (tabs) of a spreadsheet
+func (h *SheetHandler) ListSheets(c *gin.Context) {
+	token := c.Param("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Spreadsheet Token is required"})
+		return
+	}
+
+	input := larksheets.NewQuerySpreadsheetSheetReqBuilder().
+		SpreadsheetToken(token).
+		Build()
+
+	resp, err := h.Client.Client.Sheets.SpreadsheetSheet.Query(context.Background(), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+
+	items := make([]models.SheetInfo, 0, len(resp.Data.Sheets))
+	for _, s := range resp.Data.Sheets {
+		items = append(items, toSheetInfo(s))
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   models.SheetListResponse{Items: items},
+	})
+}
+
+// AddSheet adds a sheet to a spreadsheet
+func (h *SheetHandler) AddSheet(c *gin.Context) {
+	token := c.Param("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Spreadsheet Token is required"})
+		return
+	}
+
+	var req models.AddSheetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	properties := map[string]interface{}{"title": req.Title}
+	if req.Index != nil {
+		properties["index"] = *req.Index
+	}
+
+	replies, err := h.batchUpdateSheets(context.Background(), token, map[string]interface{}{
+		"addSheet": map[string]interface{}{"properties": properties},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	var added struct {
+		Properties struct {
+			SheetID string `json:"sheetId"`
+			Title   string `json:"title"`
+			Index   int    `json:"index"`
+		} `json:"properties"`
+	}
+	if len(replies) > 0 {
+		json.Unmarshal(replies[0]["addSheet"], &added)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data: models.SheetInfo{
+			SheetID: added.Properties.SheetID,
+			Title:   added.Properties.Title,
+			Index:   added.Properties.Index,
+		},
+	})
+}
+
+// DeleteSheet deletes a sheet from a spreadsheet
+func (h *SheetHandler) DeleteSheet(c *gin.Context) {
+	token := c.Param("token")
+	sheetID := c.Param("sheet_id")
+	if token == "" || sheetID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Spreadsheet Token and Sheet ID are required"})
+		return
+	}
+
+	_, err := h.batchUpdateSheets(context.Background(), token, map[string]interface{}{
+		"deleteSheet": map[string]interface{}{"sheetId": sheetID},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{Status: "success", Message: "Sheet deleted"})
+}
+
+// ReadSheetRange reads a range as a 2D array, or with format=records as objects keyed by the first row
+func (h *SheetHandler) ReadSheetRange(c *gin.Context) {
+	token := c.Param("token")
+	sheetID := c.Param("sheet_id")
+	if token == "" || sheetID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Spreadsheet Token and Sheet ID are required"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "rows")
+	if format != "rows" && format != "records" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "format must be rows or records"})
+		return
+	}
+
+	vr, err := h.readRange(context.Background(), token, sheetRange(sheetID, c.Query("range")), c.Query("value_render_option"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	data := models.SheetRangeResponse{
+		Range:    vr.Range,
+		Revision: vr.Revision,
+	}
+	if format == "rows" {
+		data.Values = vr.Values
+		if data.Values == nil {
+			data.Values = [][]interface{}{}
+		}
+	} else {
+		data.Headers, data.Records = toSheetRecords(vr.Values)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   data,
+	})
+}
+
+// WriteSheetRange writes a 2D array starting at the given cell, overwriting what is there
+func (h *SheetHandler) WriteSheetRange(c *gin.Context) {
+	token := c.Param("token")
+	sheetID := c.Param("sheet_id")
+	if token == "" || sheetID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Spreadsheet Token and Sheet ID are required"})
+		return
+	}
+
+	var req models.WriteSheetRangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if _, _, err := parseCell(req.Range); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	result, err := h.writeValues(context.Background(), token, sheetID, req.Range, req.Values)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   toSheetWriteResponse(result),
+	})
+}
+
+// AppendSheetRows appends rows after the last non-empty row. Records are mapped onto columns by the header row.
+func (h *SheetHandler) AppendSheetRows(c *gin.Context) {
+	token := c.Param("token")
+	sheetID := c.Param("sheet_id")
+	if token == "" || sheetID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Spreadsheet Token and Sheet ID are required"})
+		return
+	}
+
+	var req models.AppendSheetRowsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if len(req.Values) == 0 && len(req.Records) == 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "values or records is required"})
+		return
+	}
+
+	ctx := context.Background()
+	values := req.Values
+	if len(req.Records) > 0 {
+		sheet, err := h.getSheet(ctx, token, sheetID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+		header, err := h.readRange(ctx, token, sheetRange(sheetID, cellRange(1, 1, 1, sheet.ColumnCount)), "")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+		if len(header.Values) > 0 {
+			// Unused columns come back as nulls; don't treat them as headers
+			row := header.Values[0]
+			for len(row) > 0 && (row[len(row)-1] == nil || row[len(row)-1] == "") {
+				row = row[:len(row)-1]
+			}
+			header.Values[0] = row
+		}
+		headers, _ := toSheetRecords(header.Values)
+
+		values, err = recordsToRows(headers, req.Records)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+	}
+
+	result, err := h.appendValues(ctx, token, sheetID, values)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   toSheetWriteResponse(result),
+	})
+}
+
+// ClearSheetRange empties the cells of a range
+func (h *SheetHandler) ClearSheetRange(c *gin.Context) {
+	token := c.Param("token")
+	sheetID := c.Param("sheet_id")
+	if token == "" || sheetID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Spreadsheet Token and Sheet ID are required"})
+		return
+	}
+
+	var req models.ClearSheetRangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	result, err := h.clearRange(context.Background(), token, sheetID, req.Range)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   toSheetWriteResponse(result),
+	})
+}
+
+func (h *SheetHandler) getSheet(ctx context.Context, token, sheetID string) (models.SheetInfo, error) {
+	input := larksheets.NewGetSpreadsheetSheetReqBuilder().
+		SpreadsheetToken(token).
+		SheetId(sheetID).
+		Build()
+
+	resp, err := h.Client.Client.Sheets.SpreadsheetSheet.Get(ctx, input)
+	if err != nil {
+		return models.SheetInfo{}, err
+	}
+	if !resp.Success() {
+		return models.SheetInfo{}, fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+	}
+	return toSheetInfo(resp.Data.Sheet), nil
+}
+
+// clearRange overwrites a range with empty strings. The v2 API has no clear call,
+// so the range is read first to learn how many cells actually hold data.
+func (h *SheetHandler) clearRange(ctx context.Context, token, sheetID, rng string) (sheetWriteResult, error) {
+	vr, err := h.readRange(ctx, token, sheetRange(sheetID, rng), "")
+	if err != nil {
+		return sheetWriteResult{}, err
+	}
+
+	blank := padRows(vr.Values)
+	for _, row := range blank {
+		for j := range row {
+			row[j] = ""
+		}
+	}
+	return h.writeValues(ctx, token, sheetID, vr.Range, blank)
+}
+
+// toSheetRecords turns the first row into headers and every following row into a map keyed by them.
+// Columns with an empty header are named by their letter.
+func toSheetRecords(values [][]interface{}) ([]string, []map[string]interface{}) {
+	headers := []string{}
+	records := []map[string]interface{}{}
+	if len(values) == 0 {
+		return headers, records
+	}
+
+	for i, v := range values[0] {
+		name := ""
+		if v != nil {
+			name = fmt.Sprint(v)
+		}
+		if name == "" {
+			name = columnName(i + 1)
+		}
+		headers = append(headers, name)
+	}
+
+	for _, row := range values[1:] {
+		record := make(map[string]interface{}, len(headers))
+		empty := true
+		for i, header := range headers {
+			var v interface{}
+			if i < len(row) {
+				v = row[i]
+			}
+			if v != nil && v != "" {
+				empty = false
+			}
+			record[header] = v
+		}
+		if !empty {
+			records = append(records, record)
+		}
+	}
+
+	return headers, records
+}
+
+func recordsToRows(headers []string, records []map[string]interface{}) ([][]interface{}, error) {
+	index := make(map[string]int, len(headers))
+	for i, h := range headers {
+		index[h] = i
+	}
+
+	rows := make([][]interface{}, 0, len(records))
+	for _, record := range records {
+		row := make([]interface{}, len(headers))
+		for key, v := range record {
+			i, ok := index[key]
+			if !ok {
+				return nil, fmt.Errorf("record field %q doesn't match a header column", key)
+			}
+			row[i] = v
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func toSheetInfo(s *larksheets.Sheet) models.SheetInfo {
+	info := models.SheetInfo{
+		SheetID: larkcore.StringValue(s.SheetId),
+		Title:   larkcore.StringValue(s.Title),
+		Index:   larkcore.IntValue(s.Index),
+		Hidden:  larkcore.BoolValue(s.Hidden),
+	}
+	if g := s.GridProperties; g != nil {
+		info.RowCount = larkcore.IntValue(g.RowCount)
+		info.ColumnCount = larkcore.IntValue(g.ColumnCount)
+	}
+	return info
+}
+
+func toSheetWriteResponse(r sheetWriteResult) models.SheetWriteResponse {
+	return models.SheetWriteResponse{
+		UpdatedRange:   r.UpdatedRange,
+		UpdatedRows:    r.UpdatedRows,
+		UpdatedColumns: r.UpdatedColumns,
+		UpdatedCells:   r.UpdatedCells,
+		Revision:       r.Revision,
+	}
+}
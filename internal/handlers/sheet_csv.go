@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"log"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"lark-integration-skill/internal/models"
+
+	"github.com/gin-gonic/gin"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+)
+
+const (
+	// csvReadPageRows is how many rows each range read fetches while exporting
+	csvReadPageRows = 1000
+
+	// maxDimensionsPerRequest is Lark's limit on rows or columns added by one dimension_range call
+	maxDimensionsPerRequest = 5000
+)
+
+var csvNumberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?$`)
+
+// ImportSheetCSV replaces a sheet's content with the CSV request body.
+// Rows are written in chunks within the API limits, the grid grows as needed and leftover cells are emptied.
+func (h *SheetHandler) ImportSheetCSV(c *gin.Context) {
+	token := c.Param("token")
+	sheetID := c.Param("sheet_id")
+	if token == "" || sheetID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Spreadsheet Token and Sheet ID are required"})
+		return
+	}
+
+	delimiter, err := csvDelimiter(c.Query("delimiter"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	reader := csv.NewReader(c.Request.Body)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Invalid CSV: " + err.Error()})
+		return
+	}
+
+	values := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		values[i] = make([]interface{}, len(row))
+		for j, field := range row {
+			values[i][j] = csvCellValue(field)
+		}
+	}
+	values = padRows(values)
+	width := 0
+	if len(values) > 0 {
+		width = len(values[0])
+	}
+
+	ctx := context.Background()
+	sheet, err := h.getSheet(ctx, token, sheetID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	if err := h.growSheet(ctx, token, sheetID, "ROWS", len(values)-sheet.RowCount); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if err := h.growSheet(ctx, token, sheetID, "COLUMNS", width-sheet.ColumnCount); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	result, err := h.writeValues(ctx, token, sheetID, "A1", values)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	// Empty whatever the old content left to the right of and below the new content
+	if width > 0 && width < sheet.ColumnCount {
+		if err := h.clearRows(ctx, token, sheetID, 1, len(values), width+1, sheet.ColumnCount); err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+	}
+	if len(values) < sheet.RowCount {
+		if err := h.clearRows(ctx, token, sheetID, len(values)+1, sheet.RowCount, 1, sheet.ColumnCount); err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   toSheetWriteResponse(result),
+	})
+}
+
+// ExportSheetCSV streams a sheet as CSV, reading it csvReadPageRows rows at a time.
+// Trailing empty rows are dropped.
+func (h *SheetHandler) ExportSheetCSV(c *gin.Context) {
+	token := c.Param("token")
+	sheetID := c.Param("sheet_id")
+	if token == "" || sheetID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Spreadsheet Token and Sheet ID are required"})
+		return
+	}
+
+	delimiter, err := csvDelimiter(c.Query("delimiter"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	valueRender := c.DefaultQuery("value_render_option", "FormattedValue")
+
+	ctx := context.Background()
+	sheet, err := h.getSheet(ctx, token, sheetID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	// Read the first page before writing anything, so early failures can still be reported as JSON
+	page, err := h.readRowPage(ctx, token, sheetID, 1, sheet, valueRender)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": sheet.Title + ".csv"}))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Comma = delimiter
+
+	width := 0
+	for _, row := range page {
+		width = max(width, usedWidth(row))
+	}
+
+	pendingBlank := 0
+	for start := 1; ; {
+		for _, row := range page {
+			n := usedWidth(row)
+			if n == 0 {
+				pendingBlank++
+				continue
+			}
+			for ; pendingBlank > 0; pendingBlank-- {
+				w.Write(make([]string, width))
+			}
+
+			record := make([]string, max(width, n))
+			for i := 0; i < n; i++ {
+				record[i] = cellString(row[i])
+			}
+			w.Write(record)
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			log.Printf("CSV export of %s!%s stopped: %v", token, sheetID, err)
+			return
+		}
+
+		start += csvReadPageRows
+		if start > sheet.RowCount {
+			return
+		}
+		page, err = h.readRowPage(ctx, token, sheetID, start, sheet, valueRender)
+		if err != nil {
+			// Headers are already sent; all that can be done is to cut the stream short
+			log.Printf("CSV export of %s!%s stopped at row %d: %v", token, sheetID, start, err)
+			return
+		}
+	}
+}
+
+// readRowPage reads up to csvReadPageRows full-width rows starting at row start
+func (h *SheetHandler) readRowPage(ctx context.Context, token, sheetID string, start int, sheet models.SheetInfo, valueRender string) ([][]interface{}, error) {
+	if sheet.RowCount == 0 || sheet.ColumnCount == 0 {
+		return nil, nil
+	}
+	rows := min(csvReadPageRows, sheet.RowCount-start+1)
+	vr, err := h.readRange(ctx, token, sheetRange(sheetID, cellRange(1, start, rows, sheet.ColumnCount)), valueRender)
+	return vr.Values, err
+}
+
+// clearRows empties rows fromRow..toRow of columns fromCol..toCol, a block of rows at a time
+func (h *SheetHandler) clearRows(ctx context.Context, token, sheetID string, fromRow, toRow, fromCol, toCol int) error {
+	for start := fromRow; start <= toRow; start += maxRowsPerWrite {
+		rows := min(maxRowsPerWrite, toRow-start+1)
+		if _, err := h.clearRange(ctx, token, sheetID, cellRange(fromCol, start, rows, toCol-fromCol+1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// growSheet adds count rows or columns ("ROWS" or "COLUMNS") to the end of a sheet
+func (h *SheetHandler) growSheet(ctx context.Context, token, sheetID, dimension string, count int) error {
+	pathParams := larkcore.PathParams{}
+	pathParams.Set("spreadsheet_token", token)
+
+	for count > 0 {
+		length := min(count, maxDimensionsPerRequest)
+		body := map[string]interface{}{
+			"dimension": map[string]interface{}{
+				"sheetId":        sheetID,
+				"majorDimension": dimension,
+				"length":         length,
+			},
+		}
+		if err := h.callSheetsAPI(ctx, http.MethodPost, "/open-apis/sheets/v2/spreadsheets/:spreadsheet_token/dimension_range", pathParams, nil, body, nil); err != nil {
+			return err
+		}
+		count -= length
+	}
+	return nil
+}
+
+func csvDelimiter(s string) (rune, error) {
+	switch s {
+	case "":
+		return ',', nil
+	case "\\t", "tab":
+		return '\t', nil
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	if size != len(s) || r == '"' || r == '\r' || r == '\n' {
+		return 0, errors.New("delimiter must be a single character")
+	}
+	return r, nil
+}
+
+// csvCellValue writes plain decimal numbers as numbers so formulas and sorting work.
+// Anything else, including values with leading zeros like IDs or ZIP codes, stays text.
+func csvCellValue(field string) interface{} {
+	if len(field) > 15 || !csvNumberPattern.MatchString(field) {
+		return field
+	}
+	f, err := strconv.ParseFloat(field, 64)
+	if err != nil {
+		return field
+	}
+	return f
+}
+
+// cellString renders a cell value for CSV. Rich text cells come back as segment arrays; their text is joined.
+func cellString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		var sb strings.Builder
+		for _, seg := range v {
+			if m, ok := seg.(map[string]interface{}); ok {
+				sb.WriteString(cellString(m["text"]))
+			} else {
+				sb.WriteString(cellString(seg))
+			}
+		}
+		return sb.String()
+	case map[string]interface{}:
+		if text, ok := v["text"]; ok {
+			return cellString(text)
+		}
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// usedWidth is the number of cells up to the last non-empty one
+func usedWidth(row []interface{}) int {
+	n := len(row)
+	for n > 0 && cellString(row[n-1]) == "" {
+		n--
+	}
+	return n
+}
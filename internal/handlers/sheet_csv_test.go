@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCSVCellValue(t *testing.T) {
+	tests := []struct {
+		in   string
+		want interface{}
+	}{
+		{"42", 42.0},
+		{"-3.5", -3.5},
+		{"0", 0.0},
+		{"0.25", 0.25},
+		{"", ""},
+		{"007", "007"},
+		{"1e5", "1e5"},
+		{"1,000", "1,000"},
+		{" 12", " 12"},
+		{"12.", "12."},
+		{"1234567890123456", "1234567890123456"},
+		{"abc", "abc"},
+	}
+	for _, tt := range tests {
+		if got := csvCellValue(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("csvCellValue(%q) = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCellString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   interface{}
+		want string
+	}{
+		{"nil", nil, ""},
+		{"string", "x", "x"},
+		{"number", 1.5, "1.5"},
+		{"large number", 1e15, "1000000000000000"},
+		{"bool", true, "true"},
+		{"rich text", []interface{}{map[string]interface{}{"text": "a"}, map[string]interface{}{"text": "b"}}, "ab"},
+		{"link", map[string]interface{}{"text": "site", "link": "https://example.com"}, "site"},
+		{"other", map[string]interface{}{"k": 1.0}, `{"k":1}`},
+	}
+	for _, tt := range tests {
+		if got := cellString(tt.in); got != tt.want {
+			t.Errorf("%s: cellString(%#v) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCSVDelimiter(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    rune
+		wantErr bool
+	}{
+		{in: "", want: ','},
+		{in: ";", want: ';'},
+		{in: "tab", want: '\t'},
+		{in: `\t`, want: '\t'},
+		{in: "|", want: '|'},
+		{in: "ab", wantErr: true},
+		{in: `"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := csvDelimiter(tt.in)
+		if (err != nil) != tt.wantErr || (!tt.wantErr && got != tt.want) {
+			t.Errorf("csvDelimiter(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestUsedWidth(t *testing.T) {
+	tests := []struct {
+		row  []interface{}
+		want int
+	}{
+		{nil, 0},
+		{[]interface{}{"a", nil, ""}, 1},
+		{[]interface{}{nil, "b"}, 2},
+		{[]interface{}{nil, ""}, 0},
+	}
+	for _, tt := range tests {
+		if got := usedWidth(tt.row); got != tt.want {
+			t.Errorf("usedWidth(%#v) = %d, want %d", tt.row, got, tt.want)
+		}
+	}
+}
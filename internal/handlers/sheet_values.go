@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+)
+
+// Limits of the Sheets v2 values API for a single write
+const (
+	maxRowsPerWrite    = 5000
+	maxColumnsPerWrite = 100
+)
+
+// sheetsAPIResponse is the envelope of the Sheets v2 endpoints, which the SDK has no typed client for
+type sheetsAPIResponse struct {
+	Code int             `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+type sheetValueRange struct {
+	Range    string          `json:"range"`
+	Revision int             `json:"revision"`
+	Values   [][]interface{} `json:"values"`
+}
+
+type sheetWriteResult struct {
+	Revision       int    `json:"revision"`
+	UpdatedRange   string `json:"updatedRange"`
+	UpdatedRows    int    `json:"updatedRows"`
+	UpdatedColumns int    `json:"updatedColumns"`
+	UpdatedCells   int    `json:"updatedCells"`
+}
+
+// callSheetsAPI sends a Sheets v2 request with the tenant token and decodes data into out (when not nil)
+func (h *SheetHandler) callSheetsAPI(ctx context.Context, method, path string, pathParams larkcore.PathParams, queryParams larkcore.QueryParams, body, out interface{}) error {
+	if pathParams == nil {
+		pathParams = larkcore.PathParams{}
+	}
+	if queryParams == nil {
+		queryParams = larkcore.QueryParams{}
+	}
+
+	resp, err := h.Client.Client.Do(ctx, &larkcore.ApiReq{
+		HttpMethod:                method,
+		ApiPath:                   path,
+		Body:                      body,
+		PathParams:                pathParams,
+		QueryParams:               queryParams,
+		SupportedAccessTokenTypes: []larkcore.AccessTokenType{larkcore.AccessTokenTypeTenant},
+	})
+	if err != nil {
+		return err
+	}
+
+	var envelope sheetsAPIResponse
+	if err := json.Unmarshal(resp.RawBody, &envelope); err != nil {
+		return fmt.Errorf("decode sheets response: %w", err)
+	}
+	if envelope.Code != 0 {
+		return fmt.Errorf("Lark API Error: %d - %s", envelope.Code, envelope.Msg)
+	}
+	if out == nil || len(envelope.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
+
+// readRange reads a range such as "sheetId!A1:D10". valueRender is passed through as valueRenderOption when set.
+func (h *SheetHandler) readRange(ctx context.Context, token, rng, valueRender string) (sheetValueRange, error) {
+	pathParams := larkcore.PathParams{}
+	pathParams.Set("spreadsheet_token", token)
+	pathParams.Set("range", rng)
+
+	queryParams := larkcore.QueryParams{}
+	if valueRender != "" {
+		queryParams.Set("valueRenderOption", valueRender)
+	}
+
+	var data struct {
+		ValueRange sheetValueRange `json:"valueRange"`
+	}
+	err := h.callSheetsAPI(ctx, http.MethodGet, "/open-apis/sheets/v2/spreadsheets/:spreadsheet_token/values/:range", pathParams, queryParams, nil, &data)
+	return data.ValueRange, err
+}
+
+// writeValues writes values with their top-left corner at startCell, split into requests within the API limits
+func (h *SheetHandler) writeValues(ctx context.Context, token, sheetID, startCell string, values [][]interface{}) (sheetWriteResult, error) {
+	col, row, err := parseCell(startCell)
+	if err != nil {
+		return sheetWriteResult{}, err
+	}
+	values = padRows(values)
+	width := 0
+	if len(values) > 0 {
+		width = len(values[0])
+	}
+
+	result := sheetWriteResult{
+		UpdatedRange:   sheetID + "!" + cellRange(col, row, len(values), width),
+		UpdatedRows:    len(values),
+		UpdatedColumns: width,
+		UpdatedCells:   len(values) * width,
+	}
+	if width == 0 {
+		return result, nil
+	}
+
+	pathParams := larkcore.PathParams{}
+	pathParams.Set("spreadsheet_token", token)
+
+	for r := 0; r < len(values); r += maxRowsPerWrite {
+		rows := values[r:min(r+maxRowsPerWrite, len(values))]
+		for c := 0; c < width; c += maxColumnsPerWrite {
+			cols := min(maxColumnsPerWrite, width-c)
+			block := make([][]interface{}, len(rows))
+			for i, rowValues := range rows {
+				block[i] = rowValues[c : c+cols]
+			}
+
+			body := map[string]interface{}{
+				"valueRange": map[string]interface{}{
+					"range":  sheetID + "!" + cellRange(col+c, row+r, len(block), cols),
+					"values": block,
+				},
+			}
+
+			var data sheetWriteResult
+			if err := h.callSheetsAPI(ctx, http.MethodPut, "/open-apis/sheets/v2/spreadsheets/:spreadsheet_token/values", pathParams, nil, body, &data); err != nil {
+				return result, err
+			}
+			result.Revision = data.Revision
+		}
+	}
+
+	return result, nil
+}
+
+// appendValues inserts rows after the last non-empty row of the sheet's columns
+func (h *SheetHandler) appendValues(ctx context.Context, token, sheetID string, values [][]interface{}) (sheetWriteResult, error) {
+	values = padRows(values)
+	if len(values) == 0 || len(values[0]) == 0 {
+		return sheetWriteResult{}, nil
+	}
+
+	pathParams := larkcore.PathParams{}
+	pathParams.Set("spreadsheet_token", token)
+
+	queryParams := larkcore.QueryParams{}
+	queryParams.Set("insertDataOption", "INSERT_ROWS")
+
+	body := map[string]interface{}{
+		"valueRange": map[string]interface{}{
+			"range":  sheetID + "!" + cellRange(1, 1, len(values), len(values[0])),
+			"values": values,
+		},
+	}
+
+	var data struct {
+		Revision int              `json:"revision"`
+		Updates  sheetWriteResult `json:"updates"`
+	}
+	if err := h.callSheetsAPI(ctx, http.MethodPost, "/open-apis/sheets/v2/spreadsheets/:spreadsheet_token/values_append", pathParams, queryParams, body, &data); err != nil {
+		return sheetWriteResult{}, err
+	}
+
+	data.Updates.Revision = data.Revision
+	return data.Updates, nil
+}
+
+// batchUpdateSheets sends one sheets_batch_update request and returns its replies
+func (h *SheetHandler) batchUpdateSheets(ctx context.Context, token string, request map[string]interface{}) ([]map[string]json.RawMessage, error) {
+	pathParams := larkcore.PathParams{}
+	pathParams.Set("spreadsheet_token", token)
+
+	body := map[string]interface{}{
+		"requests": []map[string]interface{}{request},
+	}
+
+	var data struct {
+		Replies []map[string]json.RawMessage `json:"replies"`
+	}
+	err := h.callSheetsAPI(ctx, http.MethodPost, "/open-apis/sheets/v2/spreadsheets/:spreadsheet_token/sheets_batch_update", pathParams, nil, body, &data)
+	return data.Replies, err
+}
+
+// padRows makes every row as wide as the widest one, filling with empty strings
+func padRows(values [][]interface{}) [][]interface{} {
+	width := 0
+	for _, row := range values {
+		width = max(width, len(row))
+	}
+
+	out := make([][]interface{}, len(values))
+	for i, row := range values {
+		if len(row) == width {
+			out[i] = row
+			continue
+		}
+		padded := make([]interface{}, width)
+		copy(padded, row)
+		for j := len(row); j < width; j++ {
+			padded[j] = ""
+		}
+		out[i] = padded
+	}
+	return out
+}
+
+// sheetRange prefixes an A1 range with its sheet, or returns the whole sheet when rng is empty
+func sheetRange(sheetID, rng string) string {
+	if rng == "" {
+		return sheetID
+	}
+	return sheetID + "!" + rng
+}
+
+// cellRange returns the A1 range of a rows x cols block starting at column col, row row (1-based)
+func cellRange(col, row, rows, cols int) string {
+	start := columnName(col) + strconv.Itoa(row)
+	end := columnName(col+max(cols, 1)-1) + strconv.Itoa(row+max(rows, 1)-1)
+	return start + ":" + end
+}
+
+// columnName converts a 1-based column number to letters: 1 is "A", 27 is "AA"
+func columnName(n int) string {
+	name := ""
+	for n > 0 {
+		n--
+		name = string(rune('A'+n%26)) + name
+		n /= 26
+	}
+	return name
+}
+
+// parseCell parses the first cell of an A1 reference such as "B12" or "B12:D20" into 1-based column and row.
+// An empty reference is "A1".
+func parseCell(ref string) (int, int, error) {
+	if ref == "" {
+		return 1, 1, nil
+	}
+	if i := strings.Index(ref, "!"); i >= 0 {
+		ref = ref[i+1:]
+	}
+	if i := strings.Index(ref, ":"); i >= 0 {
+		ref = ref[:i]
+	}
+	ref = strings.ToUpper(strings.TrimSpace(ref))
+
+	col := 0
+	i := 0
+	for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		col = col*26 + int(ref[i]-'A'+1)
+	}
+	row, err := strconv.Atoi(ref[i:])
+	if col == 0 || err != nil || row < 1 {
+		return 0, 0, fmt.Errorf("invalid cell reference %q", ref)
+	}
+	return col, row, nil
+}
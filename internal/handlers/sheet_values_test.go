@@ -0,0 +1,80 @@
+package handlers
+
+import "testing"
+
+func TestColumnName(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{1, "A"},
+		{26, "Z"},
+		{27, "AA"},
+		{52, "AZ"},
+		{53, "BA"},
+		{702, "ZZ"},
+		{703, "AAA"},
+	}
+	for _, tt := range tests {
+		if got := columnName(tt.n); got != tt.want {
+			t.Errorf("columnName(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestParseCell(t *testing.T) {
+	tests := []struct {
+		ref     string
+		col     int
+		row     int
+		wantErr bool
+	}{
+		{ref: "", col: 1, row: 1},
+		{ref: "A1", col: 1, row: 1},
+		{ref: "b12", col: 2, row: 12},
+		{ref: "AA3:C9", col: 27, row: 3},
+		{ref: "Sheet1!D4:E5", col: 4, row: 4},
+		{ref: "12", wantErr: true},
+		{ref: "A", wantErr: true},
+		{ref: "A0", wantErr: true},
+		{ref: "A-1", wantErr: true},
+	}
+	for _, tt := range tests {
+		col, row, err := parseCell(tt.ref)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseCell(%q) = %d, %d, want an error", tt.ref, col, row)
+			}
+			continue
+		}
+		if err != nil || col != tt.col || row != tt.row {
+			t.Errorf("parseCell(%q) = %d, %d, %v, want %d, %d", tt.ref, col, row, err, tt.col, tt.row)
+		}
+	}
+}
+
+func TestParseCellRoundTrip(t *testing.T) {
+	for n := 1; n <= 1000; n++ {
+		col, _, err := parseCell(columnName(n) + "1")
+		if err != nil || col != n {
+			t.Fatalf("parseCell(columnName(%d)) = %d, %v", n, col, err)
+		}
+	}
+}
+
+func TestCellRange(t *testing.T) {
+	tests := []struct {
+		col, row, rows, cols int
+		want                 string
+	}{
+		{1, 1, 1, 1, "A1:A1"},
+		{2, 3, 2, 3, "B3:D4"},
+		{26, 1, 1, 2, "Z1:AA1"},
+		{1, 1, 0, 0, "A1:A1"},
+	}
+	for _, tt := range tests {
+		if got := cellRange(tt.col, tt.row, tt.rows, tt.cols); got != tt.want {
+			t.Errorf("cellRange(%d, %d, %d, %d) = %q, want %q", tt.col, tt.row, tt.rows, tt.cols, got, tt.want)
+		}
+	}
+}
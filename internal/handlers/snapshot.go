@@ -0,0 +1,372 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"lark-integration-skill/internal/models"
+
+	"github.com/gin-gonic/gin"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+)
+
+// Kinds of block mutation recorded by the snapshot store
+const (
+	OperationCreate = "create"
+	OperationUpdate = "update"
+	OperationDelete = "delete"
+)
+
+// maxOperationsPerDoc bounds how many operations are kept per document
+const maxOperationsPerDoc = 50
+
+// Errors a snapshot pick function returns for bad requests rather than Lark failures
+var (
+	errSnapshotBlockNotFound = errors.New("block not found")
+	errSnapshotRange         = errors.New("child range out of bounds")
+)
+
+// docOperation is a recorded mutation plus the state needed to revert it
+type docOperation struct {
+	ID         int64
+	Kind       string
+	DocumentID string
+	BlockID    string            // Parent block for create/delete, target block for update
+	Index      int               // First affected child index for create/delete
+	BlockIDs   []string          // Created block IDs (create) or removed top-level block IDs (delete, update)
+	Blocks     []*larkdocx.Block // Affected blocks and their descendants as they were before the change
+	CreateTime int64
+}
+
+func (op *docOperation) summary() models.DocOperationResponse {
+	return models.DocOperationResponse{
+		OperationID: op.ID,
+		Kind:        op.Kind,
+		BlockID:     op.BlockID,
+		Index:       op.Index,
+		BlockCount:  len(op.BlockIDs),
+		CreateTime:  op.CreateTime,
+	}
+}
+
+// SnapshotStore keeps a bounded per-document history of block mutations made through this service
+type SnapshotStore struct {
+	mu     sync.Mutex
+	nextID int64
+	ops    map[string][]*docOperation
+}
+
+func NewSnapshotStore() *SnapshotStore {
+	return &SnapshotStore{ops: make(map[string][]*docOperation)}
+}
+
+func (s *SnapshotStore) Record(op *docOperation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	op.ID = s.nextID
+	op.CreateTime = time.Now().Unix()
+
+	history := append(s.ops[op.DocumentID], op)
+	if len(history) > maxOperationsPerDoc {
+		history = history[len(history)-maxOperationsPerDoc:]
+	}
+	s.ops[op.DocumentID] = history
+}
+
+// Pop removes and returns the most recent operation for a document, so concurrent undos never get the same one
+func (s *SnapshotStore) Pop(documentID string) (*docOperation, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := s.ops[documentID]
+	if len(history) == 0 {
+		return nil, false
+	}
+	op := history[len(history)-1]
+	s.ops[documentID] = history[:len(history)-1]
+	return op, true
+}
+
+// Restore puts back an operation whose revert failed, in ID order among any recorded since
+func (s *SnapshotStore) Restore(op *docOperation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := s.ops[op.DocumentID]
+	i := len(history)
+	for i > 0 && history[i-1].ID > op.ID {
+		i--
+	}
+	history = append(history[:i:i], append([]*docOperation{op}, history[i:]...)...)
+	if len(history) > maxOperationsPerDoc {
+		history = history[len(history)-maxOperationsPerDoc:]
+	}
+	s.ops[op.DocumentID] = history
+}
+
+// List returns the recorded operations for a document, most recent first
+func (s *SnapshotStore) List(documentID string) []models.DocOperationResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := s.ops[documentID]
+	items := make([]models.DocOperationResponse, 0, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		items = append(items, history[i].summary())
+	}
+	return items
+}
+
+// ListDocOperations lists the block mutations that can be undone for a document
+func (h *DocHandler) ListDocOperations(c *gin.Context) {
+	docToken := c.Param("doc_token")
+	if docToken == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Doc Token is required"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data: models.DocOperationListResponse{
+			Items: h.Snapshots.List(docToken),
+		},
+	})
+}
+
+// UndoDocOperations reverts the last N block mutations made through this service, most recent first
+func (h *DocHandler) UndoDocOperations(c *gin.Context) {
+	docToken := c.Param("doc_token")
+	if docToken == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Doc Token is required"})
+		return
+	}
+
+	var req models.UndoDocRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+	}
+
+	steps := req.Steps
+	if steps <= 0 {
+		steps = 1
+	}
+
+	ctx := context.Background()
+	reverted := make([]models.DocOperationResponse, 0, steps)
+
+	for i := 0; i < steps; i++ {
+		op, ok := h.Snapshots.Pop(docToken)
+		if !ok {
+			break
+		}
+
+		if err := h.revertOperation(ctx, op); err != nil {
+			h.Snapshots.Restore(op)
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Status:  "error",
+				Message: fmt.Sprintf("Failed to revert %s operation %d: %v", op.Kind, op.ID, err),
+				Data:    models.UndoDocResponse{Reverted: reverted},
+			})
+			return
+		}
+
+		reverted = append(reverted, op.summary())
+	}
+
+	if len(reverted) == 0 {
+		c.JSON(http.StatusNotFound, models.APIResponse{Status: "error", Message: "No operations to undo"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   models.UndoDocResponse{Reverted: reverted},
+	})
+}
+
+// snapshotBlocks captures the blocks selected by pick, along with their descendants
+func (h *DocHandler) snapshotBlocks(ctx context.Context, documentID string, pick func(byID map[string]*larkdocx.Block) ([]string, error)) ([]string, []*larkdocx.Block, error) {
+	blocks, err := h.fetchAllBlocks(ctx, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byID := indexBlocks(blocks)
+	rootIDs, err := pick(byID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rootIDs, collectSubtree(byID, rootIDs), nil
+}
+
+// snapshotErrorStatus maps a snapshotBlocks error to the HTTP status the caller should return
+func snapshotErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, errSnapshotBlockNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errSnapshotRange):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *DocHandler) revertOperation(ctx context.Context, op *docOperation) error {
+	switch op.Kind {
+	case OperationCreate:
+		index, err := h.findChildRange(ctx, op.DocumentID, op.BlockID, op.BlockIDs)
+		if err != nil {
+			return err
+		}
+		return h.deleteChildRange(ctx, op.DocumentID, op.BlockID, index, index+len(op.BlockIDs))
+
+	case OperationDelete:
+		return h.restoreBlocks(ctx, op.DocumentID, op.BlockID, op.Index, op.BlockIDs, op.Blocks)
+
+	case OperationUpdate:
+		if len(op.Blocks) == 0 {
+			return fmt.Errorf("no snapshot recorded")
+		}
+		original := op.Blocks[0]
+
+		// Text-like blocks are patched back in place so their block ID survives
+		if text := blockText(original); text != nil {
+			return h.restoreTextElements(ctx, op.DocumentID, op.BlockID, text.Elements)
+		}
+
+		// Anything else is replaced with the snapshot, which gives it a new block ID
+		parentID := larkcore.StringValue(original.ParentId)
+		index, err := h.findChildRange(ctx, op.DocumentID, parentID, []string{op.BlockID})
+		if err != nil {
+			return err
+		}
+		if err := h.deleteChildRange(ctx, op.DocumentID, parentID, index, index+1); err != nil {
+			return err
+		}
+		return h.restoreBlocks(ctx, op.DocumentID, parentID, index, op.BlockIDs, op.Blocks)
+	}
+
+	return fmt.Errorf("unknown operation kind %q", op.Kind)
+}
+
+// findChildRange returns the index at which blockIDs appear, contiguously, among the parent's children
+func (h *DocHandler) findChildRange(ctx context.Context, documentID, parentID string, blockIDs []string) (int, error) {
+	if len(blockIDs) == 0 {
+		return 0, fmt.Errorf("the operation recorded no blocks under %s", parentID)
+	}
+
+	input := larkdocx.NewGetDocumentBlockReqBuilder().
+		DocumentId(documentID).
+		BlockId(parentID).
+		Build()
+
+	resp, err := h.Client.Client.Docx.DocumentBlock.Get(ctx, input)
+	if err != nil {
+		return 0, err
+	}
+	if !resp.Success() {
+		return 0, fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+	}
+
+	children := resp.Data.Block.Children
+	for i := range children {
+		if children[i] != blockIDs[0] {
+			continue
+		}
+		if i+len(blockIDs) > len(children) {
+			break
+		}
+		for j, id := range blockIDs {
+			if children[i+j] != id {
+				return 0, fmt.Errorf("blocks under %s have been reordered since the operation", parentID)
+			}
+		}
+		return i, nil
+	}
+
+	return 0, fmt.Errorf("blocks are no longer children of %s", parentID)
+}
+
+func (h *DocHandler) deleteChildRange(ctx context.Context, documentID, parentID string, start, end int) error {
+	input := larkdocx.NewBatchDeleteDocumentBlockChildrenReqBuilder().
+		DocumentId(documentID).
+		BlockId(parentID).
+		Body(larkdocx.NewBatchDeleteDocumentBlockChildrenReqBodyBuilder().
+			StartIndex(start).
+			EndIndex(end).
+			Build()).
+		Build()
+
+	resp, err := h.Client.Client.Docx.DocumentBlockChildren.BatchDelete(ctx, input)
+	if err != nil {
+		return err
+	}
+	if !resp.Success() {
+		return fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+	}
+	return nil
+}
+
+// restoreBlocks recreates snapshotted blocks, including nested children, under parentID at index.
+// The original block IDs are reused as temporary IDs; Lark assigns new ones.
+func (h *DocHandler) restoreBlocks(ctx context.Context, documentID, parentID string, index int, rootIDs []string, blocks []*larkdocx.Block) error {
+	descendants := make([]*larkdocx.Block, 0, len(blocks))
+	for _, b := range blocks {
+		clone := *b
+		clone.ParentId = nil
+		clone.CommentIds = nil
+		descendants = append(descendants, &clone)
+	}
+
+	input := larkdocx.NewCreateDocumentBlockDescendantReqBuilder().
+		DocumentId(documentID).
+		BlockId(parentID).
+		Body(larkdocx.NewCreateDocumentBlockDescendantReqBodyBuilder().
+			ChildrenId(rootIDs).
+			Index(index).
+			Descendants(descendants).
+			Build()).
+		Build()
+
+	resp, err := h.Client.Client.Docx.DocumentBlockDescendant.Create(ctx, input)
+	if err != nil {
+		return err
+	}
+	if !resp.Success() {
+		return fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+	}
+	return nil
+}
+
+// restoreTextElements puts a text-like block's original elements back. Text style changes are not reverted.
+func (h *DocHandler) restoreTextElements(ctx context.Context, documentID, blockID string, elements []*larkdocx.TextElement) error {
+	input := larkdocx.NewPatchDocumentBlockReqBuilder().
+		DocumentId(documentID).
+		BlockId(blockID).
+		UpdateBlockRequest(larkdocx.NewUpdateBlockRequestBuilder().
+			UpdateTextElements(larkdocx.NewUpdateTextElementsRequestBuilder().
+				Elements(elements).
+				Build()).
+			Build()).
+		Build()
+
+	resp, err := h.Client.Client.Docx.DocumentBlock.Patch(ctx, input)
+	if err != nil {
+		return err
+	}
+	if !resp.Success() {
+		return fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func operationIDs(s *SnapshotStore, documentID string) []int64 {
+	var ids []int64
+	for _, op := range s.List(documentID) {
+		ids = append(ids, op.OperationID)
+	}
+	return ids
+}
+
+func TestSnapshotStorePopRestore(t *testing.T) {
+	s := NewSnapshotStore()
+	for i := 0; i < 3; i++ {
+		s.Record(&docOperation{Kind: OperationCreate, DocumentID: "doc"})
+	}
+
+	op, ok := s.Pop("doc")
+	if !ok || op.ID != 3 {
+		t.Fatalf("Pop = %v, %v, want operation 3", op, ok)
+	}
+	if got := operationIDs(s, "doc"); !reflect.DeepEqual(got, []int64{2, 1}) {
+		t.Errorf("after Pop, operations = %v, want [2 1]", got)
+	}
+
+	// An operation recorded while the revert ran stays the most recent
+	s.Record(&docOperation{Kind: OperationUpdate, DocumentID: "doc"})
+	s.Restore(op)
+	if got := operationIDs(s, "doc"); !reflect.DeepEqual(got, []int64{4, 3, 2, 1}) {
+		t.Errorf("after Restore, operations = %v, want [4 3 2 1]", got)
+	}
+
+	if _, ok := s.Pop("other"); ok {
+		t.Error("Pop on a document without history should report false")
+	}
+}
+
+func TestSnapshotErrorStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{fmt.Errorf("%w: blk_1", errSnapshotBlockNotFound), http.StatusNotFound},
+		{fmt.Errorf("%w: [2, 1) for 3 children", errSnapshotRange), http.StatusBadRequest},
+		{fmt.Errorf("Lark API Error: 99991663 - token expired"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := snapshotErrorStatus(tt.err); got != tt.want {
+			t.Errorf("snapshotErrorStatus(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"lark-integration-skill/internal/models"
+
+	"github.com/gin-gonic/gin"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+	larkdrive "github.com/larksuite/oapi-sdk-go/v3/service/drive/v1"
+)
+
+// GetDocumentRevision returns the latest revision id of a Docx file.
+// Pass it as revision_id to GetDocumentBlocks to read the document as of that revision.
+// The open API has no listing of individual revisions; named versions are the browsable history.
+func (h *DocHandler) GetDocumentRevision(c *gin.Context) {
+	docToken := c.Param("doc_token")
+	if docToken == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Doc Token is required"})
+		return
+	}
+
+	input := larkdocx.NewGetDocumentReqBuilder().
+		DocumentId(docToken).
+		Build()
+
+	resp, err := h.Client.Client.Docx.Document.Get(context.Background(), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+
+	doc := resp.Data.Document
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data: models.DocRevisionResponse{
+			DocToken:   docToken,
+			Title:      larkcore.StringValue(doc.Title),
+			RevisionID: larkcore.IntValue(doc.RevisionId),
+		},
+	})
+}
+
+// ListDocVersions lists the named versions of a document with their creator and timestamps.
+// Unnamed revisions are not included.
+func (h *DocHandler) ListDocVersions(c *gin.Context) {
+	docToken := c.Param("doc_token")
+	if docToken == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Doc Token is required"})
+		return
+	}
+
+	objType := c.DefaultQuery("obj_type", "docx")
+	pageToken := c.Query("page_token")
+	pageSizeStr := c.DefaultQuery("page_size", "50")
+
+	var pageSize int
+	fmt.Sscanf(pageSizeStr, "%d", &pageSize)
+
+	inputBuilder := larkdrive.NewListFileVersionReqBuilder().
+		FileToken(docToken).
+		ObjType(objType).
+		PageSize(pageSize).
+		UserIdType("open_id")
+
+	if pageToken != "" {
+		inputBuilder.PageToken(pageToken)
+	}
+
+	resp, err := h.Client.Client.Drive.FileVersion.List(context.Background(), inputBuilder.Build())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+
+	items := make([]models.DocVersion, 0, len(resp.Data.Items))
+	for _, v := range resp.Data.Items {
+		items = append(items, toDocVersion(v))
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data: models.DocVersionListResponse{
+			Items:     items,
+			HasMore:   larkcore.BoolValue(resp.Data.HasMore),
+			PageToken: larkcore.StringValue(resp.Data.PageToken),
+		},
+	})
+}
+
+// GetDocVersion retrieves a single named version of a document
+func (h *DocHandler) GetDocVersion(c *gin.Context) {
+	docToken := c.Param("doc_token")
+	versionID := c.Param("version_id")
+	if docToken == "" || versionID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Doc Token and Version ID are required"})
+		return
+	}
+
+	input := larkdrive.NewGetFileVersionReqBuilder().
+		FileToken(docToken).
+		VersionId(versionID).
+		ObjType(c.DefaultQuery("obj_type", "docx")).
+		UserIdType("open_id").
+		Build()
+
+	resp, err := h.Client.Client.Drive.FileVersion.Get(context.Background(), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+
+	d := resp.Data
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data: toDocVersion(&larkdrive.Version{
+			Name:       d.Name,
+			Version:    d.Version,
+			OwnerId:    d.OwnerId,
+			CreatorId:  d.CreatorId,
+			CreateTime: d.CreateTime,
+			UpdateTime: d.UpdateTime,
+			Status:     d.Status,
+			ObjType:    d.ObjType,
+		}),
+	})
+}
+
+// CreateDocVersion saves the current state of a document as a named version
+func (h *DocHandler) CreateDocVersion(c *gin.Context) {
+	docToken := c.Param("doc_token")
+	if docToken == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Doc Token is required"})
+		return
+	}
+
+	var req models.CreateDocVersionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	objType := req.ObjType
+	if objType == "" {
+		objType = "docx"
+	}
+
+	input := larkdrive.NewCreateFileVersionReqBuilder().
+		FileToken(docToken).
+		UserIdType("open_id").
+		Version(larkdrive.NewVersionBuilder().
+			Name(req.Name).
+			ObjType(objType).
+			Build()).
+		Build()
+
+	resp, err := h.Client.Client.Drive.FileVersion.Create(context.Background(), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+
+	d := resp.Data
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data: toDocVersion(&larkdrive.Version{
+			Name:       d.Name,
+			Version:    d.Version,
+			OwnerId:    d.OwnerId,
+			CreatorId:  d.CreatorId,
+			CreateTime: d.CreateTime,
+			UpdateTime: d.UpdateTime,
+			Status:     d.Status,
+			ObjType:    d.ObjType,
+		}),
+	})
+}
+
+func toDocVersion(v *larkdrive.Version) models.DocVersion {
+	return models.DocVersion{
+		VersionID:  larkcore.StringValue(v.Version),
+		Name:       larkcore.StringValue(v.Name),
+		ObjType:    larkcore.StringValue(v.ObjType),
+		CreatorID:  larkcore.StringValue(v.CreatorId),
+		OwnerID:    larkcore.StringValue(v.OwnerId),
+		CreateTime: larkcore.StringValue(v.CreateTime),
+		UpdateTime: larkcore.StringValue(v.UpdateTime),
+		Status:     larkcore.StringValue(v.Status),
+	}
+}
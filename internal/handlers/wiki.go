@@ -4,24 +4,30 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"lark-integration-skill/internal/models"
 	"lark-integration-skill/pkg/larkclient"
 
 	"github.com/gin-gonic/gin"
-	larksearch "github.com/larksuite/oapi-sdk-go/v3/service/search/v2"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
 	larkwiki "github.com/larksuite/oapi-sdk-go/v3/service/wiki/v2"
 )
 
 type WikiHandler struct {
-	Client *larkclient.ClientWrapper
+	Client        *larkclient.ClientWrapper
+	ArchiveParent string // Default wiki node for ArchiveWikiNode, from config
+	FilesRoot     string // Directory that Markdown sync and export paths resolve under; empty disables them
 }
 
-func NewWikiHandler(client *larkclient.ClientWrapper) *WikiHandler {
-	return &WikiHandler{Client: client}
+func NewWikiHandler(client *larkclient.ClientWrapper, filesRoot, archiveParent string) *WikiHandler {
+	return &WikiHandler{Client: client, FilesRoot: filesRoot, ArchiveParent: archiveParent}
 }
 
-// SearchWikiNode searches for Wiki nodes
+// SearchWikiNode searches docs and wiki nodes, returning typed results.
+// With max_results set it follows page tokens until that many results are collected or maxSearchPages
+// pages have been read. Pages are returned whole, and the page token continues after the last one returned.
 func (h *WikiHandler) SearchWikiNode(c *gin.Context) {
 	var req models.WikiSearchRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -29,31 +35,53 @@ func (h *WikiHandler) SearchWikiNode(c *gin.Context) {
 		return
 	}
 
-	input := larksearch.NewSearchDocWikiReqBuilder().
-		Body(larksearch.NewSearchDocWikiReqBodyBuilder().
-			Query(req.Query).
-			PageSize(req.PageSize).
-			PageToken(req.PageToken).
-			Build()).
-		Build()
-
-	resp, err := h.Client.Client.Search.DocWiki.Search(context.Background(), input)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
-		return
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+	maxResults := min(req.MaxResults, maxSearchResults)
+
+	data := models.WikiSearchResponse{Items: []models.WikiSearchResult{}}
+	pageToken := req.PageToken
+
+	for page := 1; ; page++ {
+		resp, err := h.Client.Client.Search.DocWiki.Search(context.Background(), buildWikiSearch(req, pageToken))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+		if !resp.Success() {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+			return
+		}
+
+		var items []models.WikiSearchResult
+		for _, unit := range resp.Data.ResUnits {
+			result := toWikiSearchResult(unit)
+			if inUpdateRange(result, req.UpdatedAfter, req.UpdatedBefore) {
+				items = append(items, result)
+			}
+		}
+
+		// Stop at a page boundary rather than cut a page, so paging on from page_token loses nothing
+		if maxResults > 0 && page > 1 && len(data.Items)+len(items) > maxResults {
+			data.HasMore = true
+			data.PageToken = pageToken
+			break
+		}
+
+		data.Items = append(data.Items, items...)
+		data.Total = larkcore.IntValue(resp.Data.Total)
+		data.HasMore = larkcore.BoolValue(resp.Data.HasMore)
+		data.PageToken = larkcore.StringValue(resp.Data.PageToken)
+
+		if len(data.Items) >= maxResults || !data.HasMore || data.PageToken == "" || page >= maxSearchPages {
+			break
+		}
+		pageToken = data.PageToken
 	}
-	if !resp.Success() {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
-		return
-	}
-
 	c.JSON(http.StatusOK, models.APIResponse{
 		Status: "success",
-		Data: models.WikiSearchResponse{
-			Items:     resp.Data.ResUnits,
-			HasMore:   *resp.Data.HasMore,
-			PageToken: *resp.Data.PageToken,
-		},
+		Data:   data,
 	})
 }
 
@@ -288,7 +316,9 @@ func (h *WikiHandler) UpdateWikiNodeTitle(c *gin.Context) {
 	})
 }
 
-// MoveDocsToWiki moves a cloud document to a Wiki space
+// MoveDocsToWiki moves a cloud document to a Wiki space.
+// Small documents move immediately; larger ones return a task, which is polled until done when wait is set.
+// With apply set and no permission to move, Lark sends the owner an approval request instead.
 func (h *WikiHandler) MoveDocsToWiki(c *gin.Context) {
 	spaceID := c.Param("space_id")
 	if spaceID == "" {
@@ -302,65 +332,51 @@ func (h *WikiHandler) MoveDocsToWiki(c *gin.Context) {
 		return
 	}
 
-	builder := larkwiki.NewMoveDocsToWikiSpaceNodeReqBuilder().
-		SpaceId(spaceID).
-		Body(larkwiki.NewMoveDocsToWikiSpaceNodeReqBodyBuilder().
-			ObjType(req.ObjType).
-			ObjToken(req.ObjToken).
-			Apply(req.Apply).
-			Build())
-
-	if req.ParentWikiToken != "" {
-		builder.Body(larkwiki.NewMoveDocsToWikiSpaceNodeReqBodyBuilder().
-			ParentWikiToken(req.ParentWikiToken).
-			ObjType(req.ObjType).
-			ObjToken(req.ObjToken).
-			Apply(req.Apply).
-			Build())
-	}
-
-	// Re-build body correctly to avoid overwriting if ParentWikiToken is present
-	bodyBuilder := larkwiki.NewMoveDocsToWikiSpaceNodeReqBodyBuilder().
-		ObjType(req.ObjType).
-		ObjToken(req.ObjToken).
-		Apply(req.Apply)
-
-	if req.ParentWikiToken != "" {
-		bodyBuilder.ParentWikiToken(req.ParentWikiToken)
-	}
-
-	input := builder.Body(bodyBuilder.Build()).Build()
-
-	resp, err := h.Client.Client.Wiki.SpaceNode.MoveDocsToWiki(context.Background(), input)
+	ctx := context.Background()
+	data, err := h.moveDocToWiki(ctx, spaceID, req.ParentWikiToken, req.ObjType, req.ObjToken, req.Apply)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
 		return
 	}
-	if !resp.Success() {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
-		return
-	}
 
-	// Safely handle nil pointers
-	wikiToken := ""
-	if resp.Data.WikiToken != nil {
-		wikiToken = *resp.Data.WikiToken
-	}
-	taskID := ""
-	if resp.Data.TaskId != nil {
-		taskID = *resp.Data.TaskId
-	}
-	applied := false
-	if resp.Data.Applied != nil {
-		applied = *resp.Data.Applied
+	if req.Wait && data.Status == MoveStatusProcessing {
+		timeout := defaultWikiTaskWait
+		if req.WaitTimeout > 0 {
+			timeout = min(time.Duration(req.WaitTimeout)*time.Second, maxWikiTaskWait)
+		}
+
+		task, err := h.waitForWikiTask(ctx, data.TaskID, timeout)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error(), Data: data})
+			return
+		}
+		data.Task = &task
+		data.Status = moveStatusFromTask(task)
+		for _, r := range task.Results {
+			if r.NodeToken != "" {
+				data.WikiToken = r.NodeToken
+				break
+			}
+		}
+
+		if data.Status == MoveStatusFailed {
+			var msgs []string
+			for _, r := range task.Results {
+				if r.StatusMsg != "" {
+					msgs = append(msgs, r.StatusMsg)
+				}
+			}
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Status:  "error",
+				Message: fmt.Sprintf("Move task failed: %s", strings.Join(msgs, "; ")),
+				Data:    data,
+			})
+			return
+		}
 	}
 
 	c.JSON(http.StatusOK, models.APIResponse{
 		Status: "success",
-		Data: models.MoveDocsToWikiResponse{
-			WikiToken: wikiToken,
-			TaskID:    taskID,
-			Applied:   applied,
-		},
+		Data:   data,
 	})
 }
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"lark-integration-skill/internal/models"
+
+	"github.com/gin-gonic/gin"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkdrive "github.com/larksuite/oapi-sdk-go/v3/service/drive/v1"
+	larkwiki "github.com/larksuite/oapi-sdk-go/v3/service/wiki/v2"
+)
+
+// archiveTitleFormat is the prefix put in front of archived node titles
+const archiveTitleFormat = "[Archived 2006-01-02] "
+
+// DeleteWikiNode moves a node's document to the trash, which removes the node.
+// A node with children needs recursive=true and, since that deletes a whole subtree,
+// a confirm token: the first call returns 409 with the token, and repeating it with ?confirm= deletes.
+func (h *WikiHandler) DeleteWikiNode(c *gin.Context) {
+	spaceID := c.Param("space_id")
+	nodeToken := c.Param("node_token")
+
+	if spaceID == "" || nodeToken == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Space ID and Node Token are required"})
+		return
+	}
+
+	recursive := c.Query("recursive") == "true"
+	confirm := c.Query("confirm")
+	ctx := context.Background()
+
+	node, err := h.getWikiNode(ctx, nodeToken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	root := toWikiTreeNode(node)
+
+	if root.HasChild {
+		if !recursive {
+			c.JSON(http.StatusConflict, models.APIResponse{Status: "error", Message: "Node has children; set recursive=true to delete the subtree"})
+			return
+		}
+
+		root.Children, _, err = h.walkWikiTree(ctx, spaceID, nodeToken, 0)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+	}
+
+	order := deletionOrder(root)
+
+	// Check for shortcuts up front, since finding one mid-delete would leave the subtree half trashed
+	if shortcuts := shortcutNodes(order); len(shortcuts) > 0 {
+		titles := make([]string, 0, len(shortcuts))
+		for _, n := range shortcuts {
+			titles = append(titles, fmt.Sprintf("%q", n.Title))
+		}
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Status:  "error",
+			Message: fmt.Sprintf("The subtree holds shortcut nodes, which can't be deleted through the API: %s", strings.Join(titles, ", ")),
+		})
+		return
+	}
+
+	if root.HasChild {
+		token := subtreeConfirmToken(order)
+		if confirm != token {
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Status:  "error",
+				Message: fmt.Sprintf("Deleting this node removes %d nodes; repeat with confirm=%s", len(order), token),
+				Data: models.DeleteWikiNodeResponse{
+					Deleted:      []string{},
+					ConfirmToken: token,
+					SubtreeSize:  len(order),
+				},
+			})
+			return
+		}
+	}
+
+	deleted := []string{}
+	for _, n := range order {
+		if err := h.deleteWikiTreeNode(ctx, n); err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Status:  "error",
+				Message: fmt.Sprintf("Delete stopped after %d nodes at %q: %v", len(deleted), n.Title, err),
+				Data: models.DeleteWikiNodeResponse{
+					Deleted:     deleted,
+					SubtreeSize: len(order),
+				},
+			})
+			return
+		}
+		deleted = append(deleted, n.NodeToken)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data: models.DeleteWikiNodeResponse{
+			Deleted:     deleted,
+			SubtreeSize: len(order),
+		},
+	})
+}
+
+// ArchiveWikiNode moves a node, with its subtree, under the archive parent and prefixes its title with the date
+func (h *WikiHandler) ArchiveWikiNode(c *gin.Context) {
+	spaceID := c.Param("space_id")
+	nodeToken := c.Param("node_token")
+
+	if spaceID == "" || nodeToken == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Space ID and Node Token are required"})
+		return
+	}
+
+	// The body is optional when an archive parent is configured
+	var req models.ArchiveWikiNodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	archiveParent := req.ArchiveParent
+	if archiveParent == "" {
+		archiveParent = h.ArchiveParent
+	}
+	if archiveParent == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "archive_parent is required when WIKI_ARCHIVE_PARENT is not configured"})
+		return
+	}
+	if archiveParent == nodeToken {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Cannot archive the archive parent"})
+		return
+	}
+
+	ctx := context.Background()
+
+	// The archive parent may live in another space
+	parent, err := h.getWikiNode(ctx, archiveParent)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	targetSpaceID := larkcore.StringValue(parent.SpaceId)
+
+	moveInput := larkwiki.NewMoveSpaceNodeReqBuilder().
+		SpaceId(spaceID).
+		NodeToken(nodeToken).
+		Body(larkwiki.NewMoveSpaceNodeReqBodyBuilder().
+			TargetParentToken(archiveParent).
+			TargetSpaceId(targetSpaceID).
+			Build()).
+		Build()
+
+	moveResp, err := h.Client.Client.Wiki.SpaceNode.Move(ctx, moveInput)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !moveResp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: moveResp.Msg})
+		return
+	}
+
+	node := moveResp.Data.Node
+	title := larkcore.StringValue(node.Title)
+	if !strings.HasPrefix(title, "[Archived ") {
+		title = time.Now().Format(archiveTitleFormat) + title
+	}
+
+	titleInput := larkwiki.NewUpdateTitleSpaceNodeReqBuilder().
+		SpaceId(targetSpaceID).
+		NodeToken(larkcore.StringValue(node.NodeToken)).
+		Body(larkwiki.NewUpdateTitleSpaceNodeReqBodyBuilder().
+			Title(title).
+			Build()).
+		Build()
+
+	titleResp, err := h.Client.Client.Wiki.SpaceNode.UpdateTitle(ctx, titleInput)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !titleResp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: "Node was moved but renaming failed: " + titleResp.Msg})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data: models.ArchiveWikiNodeResponse{
+			NodeToken:   larkcore.StringValue(node.NodeToken),
+			SpaceID:     targetSpaceID,
+			ParentToken: archiveParent,
+			Title:       title,
+		},
+	})
+}
+
+// deletionOrder lists a subtree children first, so no node is deleted before its descendants
+func deletionOrder(root *models.WikiTreeNode) []*models.WikiTreeNode {
+	var order []*models.WikiTreeNode
+	var walk func(n *models.WikiTreeNode)
+	walk = func(n *models.WikiTreeNode) {
+		for _, child := range n.Children {
+			walk(child)
+		}
+		order = append(order, n)
+	}
+	walk(root)
+	return order
+}
+
+// shortcutNodes returns the shortcut nodes among nodes
+func shortcutNodes(nodes []*models.WikiTreeNode) []*models.WikiTreeNode {
+	var shortcuts []*models.WikiTreeNode
+	for _, n := range nodes {
+		if n.NodeType == "shortcut" {
+			shortcuts = append(shortcuts, n)
+		}
+	}
+	return shortcuts
+}
+
+// subtreeConfirmToken fingerprints the nodes to be deleted, so a token stops working if the subtree changes
+func subtreeConfirmToken(nodes []*models.WikiTreeNode) string {
+	tokens := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		tokens = append(tokens, n.NodeToken)
+	}
+	sort.Strings(tokens)
+
+	sum := sha256.Sum256([]byte(strings.Join(tokens, ",")))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (h *WikiHandler) deleteWikiTreeNode(ctx context.Context, n *models.WikiTreeNode) error {
+	// A shortcut's obj token belongs to the original document, which must not be trashed
+	if n.NodeType == "shortcut" {
+		return fmt.Errorf("shortcut nodes can't be deleted through the API")
+	}
+	return h.deleteWikiDocument(ctx, n.ObjToken, n.ObjType)
+}
+
+// deleteWikiDocument moves a wiki node's document to the trash, which also removes the node
+func (h *WikiHandler) deleteWikiDocument(ctx context.Context, objToken, objType string) error {
+	input := larkdrive.NewDeleteFileReqBuilder().
+		FileToken(objToken).
+		Type(objType).
+		Build()
+
+	resp, err := h.Client.Client.Drive.File.Delete(ctx, input)
+	if err != nil {
+		return err
+	}
+	if !resp.Success() {
+		return fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+	}
+	return nil
+}
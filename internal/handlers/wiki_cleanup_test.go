@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+
+	"lark-integration-skill/internal/models"
+)
+
+func testTree() *models.WikiTreeNode {
+	return &models.WikiTreeNode{NodeToken: "root", Children: []*models.WikiTreeNode{
+		{NodeToken: "a", Children: []*models.WikiTreeNode{
+			{NodeToken: "a1"},
+			{NodeToken: "a2"},
+		}},
+		{NodeToken: "b"},
+	}}
+}
+
+func nodeTokens(nodes []*models.WikiTreeNode) []string {
+	tokens := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		tokens = append(tokens, n.NodeToken)
+	}
+	return tokens
+}
+
+func TestDeletionOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		root *models.WikiTreeNode
+		want []string
+	}{
+		{"leaf", &models.WikiTreeNode{NodeToken: "x"}, []string{"x"}},
+		{"tree", testTree(), []string{"a1", "a2", "a", "b", "root"}},
+	}
+	for _, tt := range tests {
+		if got := nodeTokens(deletionOrder(tt.root)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: deletionOrder = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSubtreeConfirmToken(t *testing.T) {
+	nodes := deletionOrder(testTree())
+	token := subtreeConfirmToken(nodes)
+
+	if len(token) != 16 {
+		t.Errorf("token %q should be 16 hex characters", token)
+	}
+
+	reversed := make([]*models.WikiTreeNode, len(nodes))
+	for i, n := range nodes {
+		reversed[len(nodes)-1-i] = n
+	}
+	if got := subtreeConfirmToken(reversed); got != token {
+		t.Errorf("token depends on order: %q vs %q", got, token)
+	}
+
+	changed := testTree()
+	changed.Children = append(changed.Children, &models.WikiTreeNode{NodeToken: "c"})
+	if got := subtreeConfirmToken(deletionOrder(changed)); got == token {
+		t.Error("token should change when a node is added")
+	}
+
+	if got := subtreeConfirmToken(nodes[1:]); got == token {
+		t.Error("token should change when a node is removed")
+	}
+}
+
+func TestShortcutNodes(t *testing.T) {
+	tree := testTree()
+	if got := shortcutNodes(deletionOrder(tree)); len(got) != 0 {
+		t.Errorf("shortcutNodes = %v, want none", nodeTokens(got))
+	}
+
+	tree.Children[0].Children[1].NodeType = "shortcut"
+	tree.Children[1].NodeType = "shortcut"
+	if got := nodeTokens(shortcutNodes(deletionOrder(tree))); !reflect.DeepEqual(got, []string{"a2", "b"}) {
+		t.Errorf("shortcutNodes = %v, want [a2 b]", got)
+	}
+}
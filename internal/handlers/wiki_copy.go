@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"lark-integration-skill/internal/models"
+
+	"github.com/gin-gonic/gin"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkwiki "github.com/larksuite/oapi-sdk-go/v3/service/wiki/v2"
+)
+
+// CopyWikiNode copies a node, or with recursive set its whole subtree, to a target space and parent
+func (h *WikiHandler) CopyWikiNode(c *gin.Context) {
+	spaceID := c.Param("space_id")
+	nodeToken := c.Param("node_token")
+
+	if spaceID == "" || nodeToken == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Space ID and Node Token are required"})
+		return
+	}
+
+	var req models.CopyWikiNodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	targetSpaceID := req.TargetSpaceID
+	if targetSpaceID == "" {
+		targetSpaceID = spaceID
+	}
+
+	cp := &wikiCopier{
+		h:         h,
+		recursive: req.Recursive,
+		created:   make(map[string]bool),
+	}
+
+	root, err := cp.copy(context.Background(), spaceID, nodeToken, targetSpaceID, req.TargetParentToken, req.Title)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Status:  "error",
+			Message: fmt.Sprintf("Copy stopped after %d nodes: %v", cp.count, err),
+			Data: models.CopyWikiNodeResponse{
+				Node:        root,
+				CopiedCount: cp.count,
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data: models.CopyWikiNodeResponse{
+			Node:        root,
+			CopiedCount: cp.count,
+		},
+	})
+}
+
+// wikiCopier copies nodes depth first, so siblings keep their order under the new parent
+type wikiCopier struct {
+	h         *WikiHandler
+	recursive bool
+	count     int
+	created   map[string]bool // Copies made so far, so copying a node into its own subtree can't recurse forever
+}
+
+// copy copies one node and, when recursive, its descendants. On error it returns the partially copied tree.
+func (cp *wikiCopier) copy(ctx context.Context, spaceID, nodeToken, targetSpaceID, targetParent, title string) (*models.WikiTreeNode, error) {
+	// List the source children before copying, so a copy placed inside the source isn't picked up as a child
+	var children []*larkwiki.Node
+	if cp.recursive {
+		var err error
+		children, err = cp.h.listAllChildNodes(ctx, spaceID, nodeToken)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	copied, err := cp.h.copyWikiNode(ctx, spaceID, nodeToken, targetSpaceID, targetParent, title)
+	if err != nil {
+		return nil, err
+	}
+	cp.count++
+
+	node := toWikiTreeNode(copied)
+	cp.created[node.NodeToken] = true
+
+	for _, child := range children {
+		childToken := larkcore.StringValue(child.NodeToken)
+		if cp.created[childToken] {
+			continue
+		}
+
+		childCopy, err := cp.copy(ctx, spaceID, childToken, targetSpaceID, node.NodeToken, "")
+		if childCopy != nil {
+			node.Children = append(node.Children, childCopy)
+		}
+		if err != nil {
+			return node, err
+		}
+	}
+
+	return node, nil
+}
+
+// copyWikiNode copies a single node. An empty title keeps Lark's default title for the copy.
+func (h *WikiHandler) copyWikiNode(ctx context.Context, spaceID, nodeToken, targetSpaceID, targetParent, title string) (*larkwiki.Node, error) {
+	bodyBuilder := larkwiki.NewCopySpaceNodeReqBodyBuilder().
+		TargetSpaceId(targetSpaceID)
+
+	if targetParent != "" {
+		bodyBuilder.TargetParentToken(targetParent)
+	}
+	if title != "" {
+		bodyBuilder.Title(title)
+	}
+
+	input := larkwiki.NewCopySpaceNodeReqBuilder().
+		SpaceId(spaceID).
+		NodeToken(nodeToken).
+		Body(bodyBuilder.Build()).
+		Build()
+
+	resp, err := h.Client.Client.Wiki.SpaceNode.Copy(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success() {
+		return nil, fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+	}
+	return resp.Data.Node, nil
+}
@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"lark-integration-skill/internal/models"
+
+	"github.com/gin-gonic/gin"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkdrive "github.com/larksuite/oapi-sdk-go/v3/service/drive/v1"
+)
+
+var (
+	markdownLinkPattern = regexp.MustCompile(`\]\(([^)\s]+)\)`)
+	larkDocURLPattern   = regexp.MustCompile(`/(?:wiki|docx)/([A-Za-z0-9]+)`)
+	unsafeNameChars     = strings.NewReplacer("/", "_", "\\", "_", ":", "_", "*", "_", "?", "_", "\"", "_", "<", "_", ">", "_", "|", "_")
+)
+
+// ExportWikiMarkdown renders every docx node of a subtree to Markdown, as a zip or a server directory.
+// A node with children is written as "Title/index.md" next to its children, the layout SyncWikiMarkdown reads back.
+// Links between exported pages become relative paths and images are saved under "images/".
+// The dir format writes only inside the handler's FilesRoot.
+func (h *WikiHandler) ExportWikiMarkdown(c *gin.Context) {
+	spaceID := c.Param("space_id")
+	if spaceID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Space ID is required"})
+		return
+	}
+
+	var req models.ExportWikiMarkdownRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	format := req.Format
+	if format == "" {
+		format = "zip"
+	}
+	if format != "zip" && format != "dir" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "format must be zip or dir"})
+		return
+	}
+	var dir string
+	if format == "dir" {
+		if req.Dir == "" {
+			c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "dir is required for the dir format"})
+			return
+		}
+		var err error
+		dir, err = resolveUnderRoot(h.FilesRoot, req.Dir)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+	}
+
+	ctx := context.Background()
+	nodes, _, err := h.walkWikiTree(ctx, spaceID, req.Root, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if req.Root != "" {
+		// Export the root page itself, not just its descendants
+		node, err := h.getWikiNode(ctx, req.Root)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+		root := toWikiTreeNode(node)
+		root.Children = nodes
+		nodes = []*models.WikiTreeNode{root}
+	}
+
+	e := &wikiMarkdownExporter{
+		docs:       &DocHandler{Client: h.Client},
+		skipImages: req.SkipImages,
+		paths:      make(map[string]string),
+		names:      map[string]bool{"images": true},
+		images:     make(map[string]string),
+	}
+	e.plan(nodes, "")
+
+	if format == "dir" {
+		e.sink = dirSink(dir)
+		if err := e.export(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.APIResponse{
+			Status: "success",
+			Data: models.ExportWikiMarkdownResponse{
+				Dir:     req.Dir,
+				Files:   e.files,
+				Images:  len(e.images),
+				Skipped: e.skipped,
+			},
+		})
+		return
+	}
+
+	// Build the zip in a temp file so a failure partway can still be reported as JSON
+	tmp, err := os.CreateTemp("", "wiki-export-*.zip")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	zw := zip.NewWriter(tmp)
+	e.sink = zipSink{zw}
+	if err := e.export(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if err := zw.Close(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	c.FileAttachment(tmp.Name(), fmt.Sprintf("wiki-%s.zip", spaceID))
+}
+
+// markdownSink receives exported files by slash-separated relative path
+type markdownSink interface {
+	WriteFile(name string, data []byte) error
+}
+
+type dirSink string
+
+func (d dirSink) WriteFile(name string, data []byte) error {
+	p := filepath.Join(string(d), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0o644)
+}
+
+type zipSink struct {
+	zw *zip.Writer
+}
+
+func (z zipSink) WriteFile(name string, data []byte) error {
+	w, err := z.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+type wikiMarkdownExporter struct {
+	docs       *DocHandler
+	sink       markdownSink
+	skipImages bool
+
+	paths   map[string]string // Node and obj tokens to exported file paths
+	names   map[string]bool   // Paths already taken, to keep sibling titles unique
+	images  map[string]string // Image tokens to exported image paths
+	files   []models.WikiMarkdownFile
+	skipped []models.WikiMarkdownFile
+}
+
+// plan assigns a file path to every docx node before anything is rendered, so links can point forward
+func (e *wikiMarkdownExporter) plan(nodes []*models.WikiTreeNode, dir string) {
+	for _, n := range nodes {
+		base := e.uniqueName(dir, safeFileName(n.Title))
+
+		filePath := base + ".md"
+		if len(n.Children) > 0 {
+			filePath = base + "/" + folderPageName
+			// Keep a child titled "index" from taking the page's own file
+			e.names[strings.ToLower(base+"/"+wikiTitle(folderPageName))] = true
+		}
+
+		if n.ObjType == "docx" {
+			file := models.WikiMarkdownFile{
+				Path:      filePath,
+				Title:     n.Title,
+				NodeToken: n.NodeToken,
+				ObjToken:  n.ObjToken,
+				ObjType:   n.ObjType,
+			}
+			e.paths[n.NodeToken] = file.Path
+			e.paths[n.ObjToken] = file.Path
+			e.files = append(e.files, file)
+		} else {
+			e.skipped = append(e.skipped, models.WikiMarkdownFile{
+				Title:     n.Title,
+				NodeToken: n.NodeToken,
+				ObjToken:  n.ObjToken,
+				ObjType:   n.ObjType,
+			})
+		}
+
+		if len(n.Children) > 0 {
+			e.plan(n.Children, base+"/")
+		}
+	}
+}
+
+func (e *wikiMarkdownExporter) uniqueName(dir, name string) string {
+	candidate := dir + name
+	for i := 2; e.names[strings.ToLower(candidate)]; i++ {
+		candidate = fmt.Sprintf("%s%s (%d)", dir, name, i)
+	}
+	e.names[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+func (e *wikiMarkdownExporter) export(ctx context.Context) error {
+	for _, f := range e.files {
+		md, err := e.render(ctx, f)
+		if err != nil {
+			return fmt.Errorf("export %q: %w", f.Title, err)
+		}
+		if err := e.sink.WriteFile(f.Path, []byte(md)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *wikiMarkdownExporter) render(ctx context.Context, f models.WikiMarkdownFile) (string, error) {
+	blocks, err := e.docs.fetchAllBlocks(ctx, f.ObjToken)
+	if err != nil {
+		return "", err
+	}
+
+	renderer := newMarkdownRenderer(blocks)
+	root, ok := renderer.byID[f.ObjToken]
+	if !ok {
+		return "# " + f.Title + "\n", nil
+	}
+
+	if !e.skipImages {
+		for _, b := range blocks {
+			if b.Image == nil {
+				continue
+			}
+			if err := e.downloadImage(ctx, larkcore.StringValue(b.Image.Token)); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	body := renderer.Render(root.Children)
+	body = markdownLinkPattern.ReplaceAllStringFunc(body, func(m string) string {
+		target := m[2 : len(m)-1]
+		if rel, ok := e.resolveLink(f.Path, target); ok {
+			return "](" + rel + ")"
+		}
+		return m
+	})
+
+	return "# " + f.Title + "\n\n" + body + "\n", nil
+}
+
+// resolveLink maps an image token or a Lark wiki/docx URL to a path relative to the file linking to it
+func (e *wikiMarkdownExporter) resolveLink(from, target string) (string, bool) {
+	to, ok := e.images[target]
+	if !ok {
+		m := larkDocURLPattern.FindStringSubmatch(target)
+		if m == nil {
+			return "", false
+		}
+		if to, ok = e.paths[m[1]]; !ok {
+			return "", false
+		}
+	}
+
+	rel, err := filepath.Rel(filepath.FromSlash(path.Dir(from)), filepath.FromSlash(to))
+	if err != nil {
+		return "", false
+	}
+	return strings.ReplaceAll(filepath.ToSlash(rel), " ", "%20"), true
+}
+
+// downloadImage saves an image once under images/, named by its token
+func (e *wikiMarkdownExporter) downloadImage(ctx context.Context, token string) error {
+	if token == "" {
+		return nil
+	}
+	if _, ok := e.images[token]; ok {
+		return nil
+	}
+
+	input := larkdrive.NewDownloadMediaReqBuilder().
+		FileToken(token).
+		Build()
+
+	resp, err := e.docs.Client.Client.Drive.Media.Download(ctx, input)
+	if err != nil {
+		return err
+	}
+	if !resp.Success() {
+		return fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+	}
+
+	data, err := io.ReadAll(resp.File)
+	if err != nil {
+		return err
+	}
+
+	ext := path.Ext(resp.FileName)
+	if ext == "" {
+		if exts, _ := mime.ExtensionsByType(http.DetectContentType(data)); len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+
+	name := "images/" + token + ext
+	if err := e.sink.WriteFile(name, data); err != nil {
+		return err
+	}
+	e.images[token] = name
+	return nil
+}
+
+func safeFileName(title string) string {
+	name := strings.TrimSpace(unsafeNameChars.Replace(title))
+	name = strings.Trim(name, ".")
+	if name == "" {
+		return "Untitled"
+	}
+	return name
+}
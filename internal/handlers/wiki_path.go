@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"lark-integration-skill/internal/models"
+
+	"github.com/gin-gonic/gin"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkwiki "github.com/larksuite/oapi-sdk-go/v3/service/wiki/v2"
+)
+
+var errWikiPathNotFound = errors.New("wiki path not found")
+
+// ResolveWikiPath finds the node at a title path such as "Engineering/Runbooks/Deploy"
+func (h *WikiHandler) ResolveWikiPath(c *gin.Context) {
+	spaceID := c.Param("space_id")
+	path := c.Query("path")
+	if spaceID == "" || path == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Space ID and path are required"})
+		return
+	}
+
+	h.respondResolvedPath(c, spaceID, models.ResolveWikiPathRequest{Path: path})
+}
+
+// ResolveOrCreateWikiPath resolves a title path, optionally creating any missing nodes along it
+func (h *WikiHandler) ResolveOrCreateWikiPath(c *gin.Context) {
+	spaceID := c.Param("space_id")
+	if spaceID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Space ID is required"})
+		return
+	}
+
+	var req models.ResolveWikiPathRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	h.respondResolvedPath(c, spaceID, req)
+}
+
+func (h *WikiHandler) respondResolvedPath(c *gin.Context, spaceID string, req models.ResolveWikiPathRequest) {
+	node, created, err := h.resolveWikiPath(context.Background(), spaceID, req.Path, req.Create, req.ObjType)
+	if errors.Is(err, errWikiPathNotFound) {
+		c.JSON(http.StatusNotFound, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data: models.ResolveWikiPathResponse{
+			Path:    req.Path,
+			Node:    toWikiNodeInfo(node),
+			Created: created,
+		},
+	})
+}
+
+// resolveWikiPath walks the space level by level, matching each path segment against node titles.
+// With create set, missing segments are created as objType nodes (default "docx").
+func (h *WikiHandler) resolveWikiPath(ctx context.Context, spaceID, path string, create bool, objType string) (*larkwiki.Node, []string, error) {
+	segments := splitWikiPath(path)
+	if len(segments) == 0 {
+		return nil, nil, fmt.Errorf("%w: empty path", errWikiPathNotFound)
+	}
+
+	var node *larkwiki.Node
+	var created []string
+	parentToken := ""
+
+	for i, title := range segments {
+		children, err := h.listAllChildNodes(ctx, spaceID, parentToken)
+		if err != nil {
+			return nil, created, err
+		}
+
+		node = findNodeByTitle(children, title)
+		if node == nil {
+			if !create {
+				return nil, created, fmt.Errorf("%w: %q has no child titled %q", errWikiPathNotFound, strings.Join(segments[:i], "/"), title)
+			}
+			node, err = h.createWikiNode(ctx, spaceID, parentToken, title, objType)
+			if err != nil {
+				return nil, created, err
+			}
+			created = append(created, title)
+		}
+
+		parentToken = larkcore.StringValue(node.NodeToken)
+	}
+
+	return node, created, nil
+}
+
+// splitWikiPath splits a path on "/" and drops empty segments, so leading or doubled slashes are ignored
+func splitWikiPath(path string) []string {
+	var segments []string
+	for _, s := range strings.Split(path, "/") {
+		if s = strings.TrimSpace(s); s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return segments
+}
+
+func findNodeByTitle(nodes []*larkwiki.Node, title string) *larkwiki.Node {
+	for _, n := range nodes {
+		if strings.TrimSpace(larkcore.StringValue(n.Title)) == title {
+			return n
+		}
+	}
+	return nil
+}
+
+// createWikiNode creates a node under parentToken (or at the space root when empty)
+func (h *WikiHandler) createWikiNode(ctx context.Context, spaceID, parentToken, title, objType string) (*larkwiki.Node, error) {
+	if objType == "" {
+		objType = "docx"
+	}
+
+	nodeBuilder := larkwiki.NewNodeBuilder().
+		ObjType(objType).
+		NodeType("origin").
+		Title(title)
+
+	if parentToken != "" {
+		nodeBuilder.ParentNodeToken(parentToken)
+	}
+
+	input := larkwiki.NewCreateSpaceNodeReqBuilder().
+		SpaceId(spaceID).
+		Node(nodeBuilder.Build()).
+		Build()
+
+	resp, err := h.Client.Client.Wiki.SpaceNode.Create(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success() {
+		return nil, fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+	}
+	return resp.Data.Node, nil
+}
+
+// getWikiNode looks up a node by its wiki token
+func (h *WikiHandler) getWikiNode(ctx context.Context, nodeToken string) (*larkwiki.Node, error) {
+	input := larkwiki.NewGetNodeSpaceReqBuilder().
+		Token(nodeToken).
+		Build()
+
+	resp, err := h.Client.Client.Wiki.Space.GetNode(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success() {
+		return nil, fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+	}
+	return resp.Data.Node, nil
+}
+
+func toWikiNodeInfo(n *larkwiki.Node) models.WikiNodeInfoResponse {
+	return models.WikiNodeInfoResponse{
+		NodeToken:       larkcore.StringValue(n.NodeToken),
+		ObjToken:        larkcore.StringValue(n.ObjToken),
+		ObjType:         larkcore.StringValue(n.ObjType),
+		ParentNodeToken: larkcore.StringValue(n.ParentNodeToken),
+		NodeType:        larkcore.StringValue(n.NodeType),
+		Title:           larkcore.StringValue(n.Title),
+		HasChild:        larkcore.BoolValue(n.HasChild),
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"regexp"
+	"strings"
+
+	"lark-integration-skill/internal/models"
+
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larksearch "github.com/larksuite/oapi-sdk-go/v3/service/search/v2"
+)
+
+const (
+	// maxSearchResults caps how many results one search request collects across pages
+	maxSearchResults = 200
+
+	// maxSearchPages caps the Lark calls of one search request, since date filters may drop every result on a page
+	maxSearchPages = 20
+)
+
+var (
+	highlightTagPattern  = regexp.MustCompile(`</?h>`)
+	highlightTermPattern = regexp.MustCompile(`<h>(.*?)</h>`)
+)
+
+// buildWikiSearch applies the request's filters to both docs and wiki results.
+// A space filter only applies to wiki nodes, so docs are left out when one is given.
+func buildWikiSearch(req models.WikiSearchRequest, pageToken string) *larksearch.SearchDocWikiReq {
+	wikiFilter := larksearch.NewWikiFilterBuilder()
+	docFilter := larksearch.NewDocFilterBuilder()
+
+	if len(req.SpaceIDs) > 0 {
+		wikiFilter.SpaceIds(req.SpaceIDs)
+	}
+	if len(req.DocTypes) > 0 {
+		wikiFilter.DocTypes(req.DocTypes)
+		docFilter.DocTypes(req.DocTypes)
+	}
+	if len(req.OwnerIDs) > 0 {
+		wikiFilter.CreatorIds(req.OwnerIDs)
+		docFilter.CreatorIds(req.OwnerIDs)
+	}
+	if req.OnlyTitle {
+		wikiFilter.OnlyTitle(true)
+		docFilter.OnlyTitle(true)
+	}
+	if req.SortType != "" {
+		wikiFilter.SortType(req.SortType)
+		docFilter.SortType(req.SortType)
+	}
+
+	bodyBuilder := larksearch.NewSearchDocWikiReqBodyBuilder().
+		Query(req.Query).
+		PageSize(req.PageSize).
+		WikiFilter(wikiFilter.Build())
+
+	if len(req.SpaceIDs) == 0 {
+		bodyBuilder.DocFilter(docFilter.Build())
+	}
+	if pageToken != "" {
+		bodyBuilder.PageToken(pageToken)
+	}
+
+	return larksearch.NewSearchDocWikiReqBuilder().
+		Body(bodyBuilder.Build()).
+		Build()
+}
+
+func toWikiSearchResult(u *larksearch.DocResUnit) models.WikiSearchResult {
+	titleHighlighted := larkcore.StringValue(u.TitleHighlighted)
+	snippetHighlighted := larkcore.StringValue(u.SummaryHighlighted)
+
+	result := models.WikiSearchResult{
+		Title:              highlightTagPattern.ReplaceAllString(titleHighlighted, ""),
+		TitleHighlighted:   titleHighlighted,
+		EntityType:         larkcore.StringValue(u.EntityType),
+		Snippet:            highlightTagPattern.ReplaceAllString(snippetHighlighted, ""),
+		SnippetHighlighted: snippetHighlighted,
+		Highlights:         highlightTerms(titleHighlighted, snippetHighlighted),
+	}
+
+	if m := u.ResultMeta; m != nil {
+		result.Token = larkcore.StringValue(m.Token)
+		result.DocType = larkcore.StringValue(m.DocTypes)
+		result.URL = larkcore.StringValue(m.Url)
+		result.OwnerID = larkcore.StringValue(m.OwnerId)
+		result.OwnerName = larkcore.StringValue(m.OwnerName)
+		result.EditUserName = larkcore.StringValue(m.EditUserName)
+		result.CreateTime = int64(larkcore.IntValue(m.CreateTime))
+		result.UpdateTime = int64(larkcore.IntValue(m.UpdateTime))
+	}
+
+	return result
+}
+
+// highlightTerms returns the distinct terms Lark marked with <h> tags, in order of appearance
+func highlightTerms(texts ...string) []string {
+	terms := []string{}
+	seen := make(map[string]bool)
+	for _, text := range texts {
+		for _, m := range highlightTermPattern.FindAllStringSubmatch(text, -1) {
+			term := strings.TrimSpace(m[1])
+			if term != "" && !seen[strings.ToLower(term)] {
+				seen[strings.ToLower(term)] = true
+				terms = append(terms, term)
+			}
+		}
+	}
+	return terms
+}
+
+func inUpdateRange(r models.WikiSearchResult, after, before int64) bool {
+	if after > 0 && r.UpdateTime < after {
+		return false
+	}
+	if before > 0 && r.UpdateTime > before {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"lark-integration-skill/internal/models"
+
+	"github.com/gin-gonic/gin"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkwiki "github.com/larksuite/oapi-sdk-go/v3/service/wiki/v2"
+)
+
+// ListWikiSpaces lists the knowledge spaces the app can see
+func (h *WikiHandler) ListWikiSpaces(c *gin.Context) {
+	pageToken := c.Query("page_token")
+	pageSizeStr := c.DefaultQuery("page_size", "20")
+
+	var pageSize int
+	fmt.Sscanf(pageSizeStr, "%d", &pageSize)
+
+	builder := larkwiki.NewListSpaceReqBuilder().
+		PageSize(pageSize)
+
+	if pageToken != "" {
+		builder.PageToken(pageToken)
+	}
+
+	resp, err := h.Client.Client.Wiki.Space.List(context.Background(), builder.Build())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+
+	items := make([]models.WikiSpace, 0, len(resp.Data.Items))
+	for _, s := range resp.Data.Items {
+		items = append(items, toWikiSpace(s))
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data: models.WikiSpaceListResponse{
+			Items:     items,
+			HasMore:   larkcore.BoolValue(resp.Data.HasMore),
+			PageToken: larkcore.StringValue(resp.Data.PageToken),
+		},
+	})
+}
+
+// GetWikiSpace retrieves information about a knowledge space
+func (h *WikiHandler) GetWikiSpace(c *gin.Context) {
+	spaceID := c.Param("space_id")
+	if spaceID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Space ID is required"})
+		return
+	}
+
+	input := larkwiki.NewGetSpaceReqBuilder().
+		SpaceId(spaceID).
+		Build()
+
+	resp, err := h.Client.Client.Wiki.Space.Get(context.Background(), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   toWikiSpace(resp.Data.Space),
+	})
+}
+
+// CreateWikiSpace creates a new knowledge space.
+// Lark only allows this with a user access token; with the app's tenant token it returns a permission error.
+func (h *WikiHandler) CreateWikiSpace(c *gin.Context) {
+	var req models.CreateWikiSpaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	input := larkwiki.NewCreateSpaceReqBuilder().
+		Space(larkwiki.NewSpaceBuilder().
+			Name(req.Name).
+			Description(req.Description).
+			Build()).
+		Build()
+
+	resp, err := h.Client.Client.Wiki.Space.Create(context.Background(), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   toWikiSpace(resp.Data.Space),
+	})
+}
+
+// UpdateWikiSpaceSettings updates who can create top-level pages, copy/export and comment in a space.
+// Lark has no read API for these settings; the response echoes the settings after the update.
+func (h *WikiHandler) UpdateWikiSpaceSettings(c *gin.Context) {
+	spaceID := c.Param("space_id")
+	if spaceID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Space ID is required"})
+		return
+	}
+
+	var req models.WikiSpaceSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	settingBuilder := larkwiki.NewSettingBuilder()
+	if req.CreateSetting != "" {
+		settingBuilder.CreateSetting(req.CreateSetting)
+	}
+	if req.SecuritySetting != "" {
+		settingBuilder.SecuritySetting(req.SecuritySetting)
+	}
+	if req.CommentSetting != "" {
+		settingBuilder.CommentSetting(req.CommentSetting)
+	}
+
+	input := larkwiki.NewUpdateSpaceSettingReqBuilder().
+		SpaceId(spaceID).
+		Setting(settingBuilder.Build()).
+		Build()
+
+	resp, err := h.Client.Client.Wiki.SpaceSetting.Update(context.Background(), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+
+	data := models.WikiSpaceSettings{}
+	if s := resp.Data.Setting; s != nil {
+		data.CreateSetting = larkcore.StringValue(s.CreateSetting)
+		data.SecuritySetting = larkcore.StringValue(s.SecuritySetting)
+		data.CommentSetting = larkcore.StringValue(s.CommentSetting)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   data,
+	})
+}
+
+// ListWikiSpaceMembers lists the members and admins of a space
+func (h *WikiHandler) ListWikiSpaceMembers(c *gin.Context) {
+	spaceID := c.Param("space_id")
+	if spaceID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Space ID is required"})
+		return
+	}
+
+	pageToken := c.Query("page_token")
+	pageSizeStr := c.DefaultQuery("page_size", "50")
+
+	var pageSize int
+	fmt.Sscanf(pageSizeStr, "%d", &pageSize)
+
+	builder := larkwiki.NewListSpaceMemberReqBuilder().
+		SpaceId(spaceID).
+		PageSize(pageSize)
+
+	if pageToken != "" {
+		builder.PageToken(pageToken)
+	}
+
+	resp, err := h.Client.Client.Wiki.SpaceMember.List(context.Background(), builder.Build())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+
+	items := make([]models.WikiSpaceMember, 0, len(resp.Data.Members))
+	for _, m := range resp.Data.Members {
+		items = append(items, toWikiSpaceMember(m))
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data: models.WikiSpaceMemberListResponse{
+			Items:     items,
+			HasMore:   larkcore.BoolValue(resp.Data.HasMore),
+			PageToken: larkcore.StringValue(resp.Data.PageToken),
+		},
+	})
+}
+
+// AddWikiSpaceMember adds a member or admin to a space
+func (h *WikiHandler) AddWikiSpaceMember(c *gin.Context) {
+	spaceID := c.Param("space_id")
+	if spaceID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Space ID is required"})
+		return
+	}
+
+	var req models.AddWikiSpaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	role := req.MemberRole
+	if role == "" {
+		role = "member"
+	}
+
+	input := larkwiki.NewCreateSpaceMemberReqBuilder().
+		SpaceId(spaceID).
+		NeedNotification(req.NeedNotification).
+		Member(larkwiki.NewMemberBuilder().
+			MemberType(req.MemberType).
+			MemberId(req.MemberID).
+			MemberRole(role).
+			Build()).
+		Build()
+
+	resp, err := h.Client.Client.Wiki.SpaceMember.Create(context.Background(), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   toWikiSpaceMember(resp.Data.Member),
+	})
+}
+
+// RemoveWikiSpaceMember removes a member or admin from a space
+func (h *WikiHandler) RemoveWikiSpaceMember(c *gin.Context) {
+	spaceID := c.Param("space_id")
+	memberID := c.Param("member_id")
+	if spaceID == "" || memberID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Space ID and Member ID are required"})
+		return
+	}
+
+	// Clients and proxies often drop DELETE bodies, so the query string works too
+	req := models.RemoveWikiSpaceMemberRequest{
+		MemberType: c.Query("member_type"),
+		MemberRole: c.Query("member_role"),
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+	}
+	if req.MemberType == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "member_type is required"})
+		return
+	}
+
+	role := req.MemberRole
+	if role == "" {
+		role = "member"
+	}
+
+	input := larkwiki.NewDeleteSpaceMemberReqBuilder().
+		SpaceId(spaceID).
+		MemberId(memberID).
+		Member(larkwiki.NewMemberBuilder().
+			MemberType(req.MemberType).
+			MemberRole(role).
+			Build()).
+		Build()
+
+	resp, err := h.Client.Client.Wiki.SpaceMember.Delete(context.Background(), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if !resp.Success() {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: resp.Msg})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{Status: "success", Message: "Member removed"})
+}
+
+func toWikiSpace(s *larkwiki.Space) models.WikiSpace {
+	if s == nil {
+		return models.WikiSpace{}
+	}
+	return models.WikiSpace{
+		SpaceID:     larkcore.StringValue(s.SpaceId),
+		Name:        larkcore.StringValue(s.Name),
+		Description: larkcore.StringValue(s.Description),
+		SpaceType:   larkcore.StringValue(s.SpaceType),
+		Visibility:  larkcore.StringValue(s.Visibility),
+		OpenSharing: larkcore.StringValue(s.OpenSharing),
+	}
+}
+
+func toWikiSpaceMember(m *larkwiki.Member) models.WikiSpaceMember {
+	if m == nil {
+		return models.WikiSpaceMember{}
+	}
+	return models.WikiSpaceMember{
+		MemberType: larkcore.StringValue(m.MemberType),
+		MemberID:   larkcore.StringValue(m.MemberId),
+		MemberRole: larkcore.StringValue(m.MemberRole),
+		Type:       larkcore.StringValue(m.Type),
+	}
+}
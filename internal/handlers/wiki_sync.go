@@ -0,0 +1,552 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"lark-integration-skill/internal/models"
+
+	"github.com/gin-gonic/gin"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+)
+
+// Wiki sync and Bitable upsert result actions
+const (
+	SyncCreated   = "created"
+	SyncUpdated   = "updated"
+	SyncUnchanged = "unchanged"
+	SyncDeleted   = "deleted"
+	SyncFailed    = "failed"
+)
+
+const (
+	defaultManifestName = ".lark-wiki.json"
+
+	// folderPageName holds the content of its directory's own node
+	folderPageName = "index.md"
+
+	// maxDescendantsPerRequest is Lark's limit on blocks created by one descendant create call
+	maxDescendantsPerRequest = 1000
+)
+
+// SyncWikiMarkdown mirrors a directory of Markdown files into a wiki subtree.
+// Folders become parent nodes and .md files become docx nodes; a folder's index.md is written into
+// the folder's own node. A manifest maps paths to nodes, so later runs only rewrite files whose content changed.
+// Both the directory and the manifest must be inside the handler's FilesRoot.
+func (h *WikiHandler) SyncWikiMarkdown(c *gin.Context) {
+	spaceID := c.Param("space_id")
+	if spaceID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Space ID is required"})
+		return
+	}
+
+	var req models.SyncWikiMarkdownRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	dir, err := resolveUnderRoot(h.FilesRoot, req.Dir)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "dir must be an existing directory"})
+		return
+	}
+
+	manifestPath := filepath.Join(dir, defaultManifestName)
+	if req.ManifestPath != "" {
+		manifestPath, err = resolveUnderRoot(h.FilesRoot, req.ManifestPath)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+			return
+		}
+	}
+
+	dirs, files, err := scanMarkdownDir(dir)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if err := titleConflicts(dirs, files); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	manifest, err := loadWikiSyncManifest(manifestPath)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+	if manifest.SpaceID == "" {
+		manifest.SpaceID = spaceID
+		manifest.ParentWikiToken = req.ParentWikiToken
+	}
+	if manifest.SpaceID != spaceID || manifest.ParentWikiToken != req.ParentWikiToken {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Manifest was written for a different space or parent node"})
+		return
+	}
+
+	s := &wikiSyncer{
+		h:            h,
+		docs:         &DocHandler{Client: h.Client},
+		dir:          dir,
+		manifestPath: manifestPath,
+		manifest:     manifest,
+	}
+
+	if err := s.sync(context.Background(), dirs, files, req.DeleteRemoved); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, r := range s.results {
+		counts[r.Action]++
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data: models.SyncWikiMarkdownResponse{
+			Results: s.results,
+			Counts:  counts,
+		},
+	})
+}
+
+type wikiSyncer struct {
+	h            *WikiHandler
+	docs         *DocHandler
+	dir          string
+	manifestPath string
+	manifest     *models.WikiSyncManifest
+	results      []models.WikiSyncResult
+}
+
+// sync creates folder nodes parents first, writes changed files, then optionally deletes what was removed.
+// Per-file failures are reported in the results; only folder and manifest errors abort the run.
+func (s *wikiSyncer) sync(ctx context.Context, dirs, files []string, deleteRemoved bool) error {
+	for _, rel := range dirs {
+		if _, ok := s.manifest.Folders[rel]; ok {
+			continue
+		}
+		entry, _, err := s.ensureNode(ctx, rel)
+		if err != nil {
+			return fmt.Errorf("create node for folder %q: %w", rel, err)
+		}
+		s.manifest.Folders[rel] = entry
+		if err := s.saveManifest(); err != nil {
+			return err
+		}
+	}
+
+	for _, rel := range files {
+		result := s.syncFile(ctx, rel)
+		s.results = append(s.results, result)
+		if result.Action == SyncUnchanged {
+			continue
+		}
+		if err := s.saveManifest(); err != nil {
+			return err
+		}
+	}
+
+	if !deleteRemoved {
+		return nil
+	}
+
+	seenFiles := make(map[string]bool, len(files))
+	for _, rel := range files {
+		seenFiles[rel] = true
+	}
+	seenDirs := make(map[string]bool, len(dirs))
+	for _, rel := range dirs {
+		seenDirs[rel] = true
+	}
+
+	s.deleteRemoved(ctx, s.manifest.Files, seenFiles)
+	s.deleteRemoved(ctx, s.manifest.Folders, seenDirs)
+
+	return s.saveManifest()
+}
+
+func (s *wikiSyncer) syncFile(ctx context.Context, rel string) models.WikiSyncResult {
+	result := models.WikiSyncResult{Path: rel}
+
+	content, err := os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(rel)))
+	if err != nil {
+		result.Action = SyncFailed
+		result.Error = err.Error()
+		return result
+	}
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+
+	entry, ok := s.manifest.Files[rel]
+	if ok && entry.Hash == hash {
+		result.Action = SyncUnchanged
+		result.NodeToken = entry.NodeToken
+		return result
+	}
+
+	result.Action = SyncUpdated
+	folder, isFolderPage := folderPageDir(rel)
+	switch {
+	case ok:
+	case isFolderPage:
+		// A folder's index.md fills the node created for the folder
+		entry = s.manifest.Folders[folder]
+	default:
+		var created bool
+		entry, created, err = s.ensureNode(ctx, rel)
+		if err != nil {
+			result.Action = SyncFailed
+			result.Error = err.Error()
+			return result
+		}
+		if created {
+			result.Action = SyncCreated
+		}
+	}
+	result.NodeToken = entry.NodeToken
+
+	if err := s.docs.replaceWithMarkdown(ctx, entry.ObjToken, string(content)); err != nil {
+		// Keep the node in the manifest without a hash so the next run retries the write
+		entry.Hash = ""
+		s.manifest.Files[rel] = entry
+		result.Action = SyncFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	entry.Hash = hash
+	s.manifest.Files[rel] = entry
+	return result
+}
+
+// ensureNode finds or creates the docx node for a path under its parent folder's node.
+// An existing child with the same title is adopted, so a lost manifest doesn't duplicate pages.
+func (s *wikiSyncer) ensureNode(ctx context.Context, rel string) (models.WikiSyncEntry, bool, error) {
+	parentToken := s.manifest.ParentWikiToken
+	if parent := path.Dir(rel); parent != "." {
+		parentToken = s.manifest.Folders[parent].NodeToken
+	}
+	title := wikiTitle(rel)
+
+	children, err := s.h.listAllChildNodes(ctx, s.manifest.SpaceID, parentToken)
+	if err != nil {
+		return models.WikiSyncEntry{}, false, err
+	}
+
+	created := false
+	node := findNodeByTitle(children, title)
+	if node == nil {
+		node, err = s.h.createWikiNode(ctx, s.manifest.SpaceID, parentToken, title, "docx")
+		if err != nil {
+			return models.WikiSyncEntry{}, false, err
+		}
+		created = true
+	}
+
+	return models.WikiSyncEntry{
+		NodeToken: larkcore.StringValue(node.NodeToken),
+		ObjToken:  larkcore.StringValue(node.ObjToken),
+	}, created, nil
+}
+
+// deleteRemoved deletes the documents behind manifest entries missing from seen, deepest paths first
+func (s *wikiSyncer) deleteRemoved(ctx context.Context, entries map[string]models.WikiSyncEntry, seen map[string]bool) {
+	var removed []string
+	for rel := range entries {
+		if !seen[rel] {
+			removed = append(removed, rel)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(removed)))
+
+	for _, rel := range removed {
+		entry := entries[rel]
+		if _, isFolderPage := folderPageDir(rel); isFolderPage {
+			// The node belongs to the folder and is deleted with it
+			delete(entries, rel)
+			continue
+		}
+		result := models.WikiSyncResult{Path: rel, Action: SyncDeleted, NodeToken: entry.NodeToken}
+		if err := s.h.deleteWikiDocument(ctx, entry.ObjToken, "docx"); err != nil {
+			result.Action = SyncFailed
+			result.Error = err.Error()
+		} else {
+			delete(entries, rel)
+		}
+		s.results = append(s.results, result)
+	}
+}
+
+func (s *wikiSyncer) saveManifest() error {
+	data, err := json.MarshalIndent(s.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.manifestPath+".tmp", data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(s.manifestPath+".tmp", s.manifestPath)
+}
+
+func loadWikiSyncManifest(manifestPath string) (*models.WikiSyncManifest, error) {
+	manifest := &models.WikiSyncManifest{}
+
+	data, err := os.ReadFile(manifestPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, manifest); err != nil {
+			return nil, fmt.Errorf("invalid manifest %s: %w", manifestPath, err)
+		}
+	}
+
+	if manifest.Folders == nil {
+		manifest.Folders = make(map[string]models.WikiSyncEntry)
+	}
+	if manifest.Files == nil {
+		manifest.Files = make(map[string]models.WikiSyncEntry)
+	}
+	return manifest, nil
+}
+
+// scanMarkdownDir returns slash-separated relative paths of the subdirectories and .md files under dir,
+// parents before children. Hidden files and directories are skipped.
+func scanMarkdownDir(dir string) ([]string, []string, error) {
+	var dirs, files []string
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == dir {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Symlinks could point outside the directory
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			dirs = append(dirs, rel)
+		} else if strings.EqualFold(filepath.Ext(rel), ".md") {
+			files = append(files, rel)
+		}
+		return nil
+	})
+
+	return dirs, files, err
+}
+
+// folderPageDir reports whether rel is a subdirectory's index.md, and returns that directory
+func folderPageDir(rel string) (string, bool) {
+	dir := path.Dir(rel)
+	return dir, dir != "." && path.Base(rel) == folderPageName
+}
+
+// wikiTitle is the node title for a synced path: the base name without its .md extension
+func wikiTitle(rel string) string {
+	title := path.Base(rel)
+	if ext := path.Ext(title); strings.EqualFold(ext, ".md") {
+		title = strings.TrimSuffix(title, ext)
+	}
+	return title
+}
+
+// titleConflicts rejects siblings that would get the same node title, such as a.md next to a directory a.
+// Nodes are matched by title, so such paths would otherwise share one node.
+func titleConflicts(dirs, files []string) error {
+	seen := make(map[string]string, len(dirs)+len(files))
+	var conflicts []string
+
+	check := func(rel string) {
+		key := path.Dir(rel) + "/" + wikiTitle(rel)
+		if other, ok := seen[key]; ok {
+			conflicts = append(conflicts, fmt.Sprintf("%q and %q", other, rel))
+			return
+		}
+		seen[key] = rel
+	}
+	for _, rel := range dirs {
+		check(rel)
+	}
+	for _, rel := range files {
+		if _, isFolderPage := folderPageDir(rel); !isFolderPage {
+			check(rel)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("these paths would map to the same wiki node; rename one, or move a folder's page to its %s: %s",
+			folderPageName, strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+// resolveUnderRoot resolves a request path against root. Relative paths start at root, and the result,
+// with symlinks followed as far as the path exists, must stay inside it.
+func resolveUnderRoot(root, p string) (string, error) {
+	if root == "" {
+		return "", errors.New("no wiki files directory is configured on the server")
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+
+	resolved := p
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(absRoot, resolved)
+	}
+	resolved = filepath.Clean(resolved)
+	if !isWithin(absRoot, resolved) {
+		return "", fmt.Errorf("%q is outside the wiki files directory", p)
+	}
+
+	// Compare real locations too, so a symlink inside root can't lead out of it
+	realRoot, err := filepath.EvalSymlinks(absRoot)
+	if err != nil {
+		// The root doesn't exist yet, so nothing under it can be a symlink
+		return resolved, nil
+	}
+	existing := resolved
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		existing = filepath.Dir(existing)
+	}
+	realExisting, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", err
+	}
+	if !isWithin(realRoot, realExisting) {
+		return "", fmt.Errorf("%q is outside the wiki files directory", p)
+	}
+	return resolved, nil
+}
+
+// isWithin reports whether the cleaned absolute path p is root or below it
+func isWithin(root, p string) bool {
+	rel, err := filepath.Rel(root, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// replaceWithMarkdown replaces the whole body of a document with converted Markdown.
+// Images aren't uploaded, so image blocks from the conversion are dropped.
+func (h *DocHandler) replaceWithMarkdown(ctx context.Context, documentID, content string) error {
+	rootResp, err := h.Client.Client.Docx.DocumentBlock.Get(ctx, larkdocx.NewGetDocumentBlockReqBuilder().
+		DocumentId(documentID).
+		BlockId(documentID).
+		Build())
+	if err != nil {
+		return err
+	}
+	if !rootResp.Success() {
+		return fmt.Errorf("Lark API Error: %d - %s", rootResp.Code, rootResp.Msg)
+	}
+	if n := len(rootResp.Data.Block.Children); n > 0 {
+		if err := h.deleteChildRange(ctx, documentID, documentID, 0, n); err != nil {
+			return err
+		}
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return nil
+	}
+
+	input := larkdocx.NewConvertDocumentReqBuilder().
+		Body(larkdocx.NewConvertDocumentReqBodyBuilder().
+			ContentType("markdown").
+			Content(content).
+			Build()).
+		Build()
+
+	resp, err := h.Client.Client.Docx.Document.Convert(ctx, input)
+	if err != nil {
+		return err
+	}
+	if !resp.Success() {
+		return fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+	}
+
+	byID := indexBlocks(resp.Data.Blocks)
+	for _, b := range resp.Data.Blocks {
+		b.Children = withoutImages(byID, b.Children)
+		if b.Table != nil && b.Table.Property != nil {
+			// The create API rejects merge_info, which the converter always fills in
+			b.Table.Property.MergeInfo = nil
+		}
+	}
+	rootIDs := withoutImages(byID, resp.Data.FirstLevelBlockIds)
+
+	// Insert the top-level blocks in batches whose subtrees fit in one request
+	index := 0
+	var batch []string
+	var batchBlocks []*larkdocx.Block
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := h.restoreBlocks(ctx, documentID, documentID, index, batch, batchBlocks); err != nil {
+			return err
+		}
+		index += len(batch)
+		batch, batchBlocks = nil, nil
+		return nil
+	}
+
+	for _, id := range rootIDs {
+		subtree := collectSubtree(byID, []string{id})
+		if len(batchBlocks)+len(subtree) > maxDescendantsPerRequest {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		batch = append(batch, id)
+		batchBlocks = append(batchBlocks, subtree...)
+	}
+	return flush()
+}
+
+func withoutImages(byID map[string]*larkdocx.Block, ids []string) []string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if b, ok := byID[id]; ok && larkcore.IntValue(b.BlockType) == blockTypeImage {
+			continue
+		}
+		out = append(out, id)
+	}
+	return out
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveUnderRoot(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{path: "docs", want: filepath.Join(root, "docs")},
+		{path: "docs/../docs/new", want: filepath.Join(root, "docs", "new")},
+		{path: ".", want: root},
+		{path: filepath.Join(root, "docs"), want: filepath.Join(root, "docs")},
+		{path: "..", wantErr: true},
+		{path: "../x", wantErr: true},
+		{path: "docs/../../x", wantErr: true},
+		{path: outside, wantErr: true},
+		{path: "/etc", wantErr: true},
+		{path: "escape", wantErr: true},
+		{path: "escape/new/file.json", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := resolveUnderRoot(root, tt.path)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("resolveUnderRoot(%q) = %q, want an error", tt.path, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("resolveUnderRoot(%q) = %q, %v, want %q", tt.path, got, err, tt.want)
+		}
+	}
+
+	if _, err := resolveUnderRoot("", "docs"); err == nil {
+		t.Error("an empty root should be rejected")
+	}
+}
+
+func TestTitleConflicts(t *testing.T) {
+	tests := []struct {
+		name    string
+		dirs    []string
+		files   []string
+		wantErr bool
+	}{
+		{name: "distinct", dirs: []string{"a"}, files: []string{"b.md", "a/c.md"}},
+		{name: "folder page", dirs: []string{"a"}, files: []string{"a/index.md", "a/b.md"}},
+		{name: "same name in different folders", dirs: []string{"a", "b"}, files: []string{"a/x.md", "b/x.md"}},
+		{name: "file next to folder", dirs: []string{"a"}, files: []string{"a.md"}, wantErr: true},
+		{name: "nested file next to folder", dirs: []string{"a", "a/b"}, files: []string{"a/b.md"}, wantErr: true},
+		{name: "extension case", files: []string{"a.md", "a.MD"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := titleConflicts(tt.dirs, tt.files); (err != nil) != tt.wantErr {
+			t.Errorf("%s: titleConflicts = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestFolderPageDir(t *testing.T) {
+	tests := []struct {
+		rel  string
+		dir  string
+		page bool
+	}{
+		{"a/index.md", "a", true},
+		{"a/b/index.md", "a/b", true},
+		{"index.md", ".", false},
+		{"a/readme.md", "a", false},
+	}
+	for _, tt := range tests {
+		dir, page := folderPageDir(tt.rel)
+		if page != tt.page || (page && dir != tt.dir) {
+			t.Errorf("folderPageDir(%q) = %q, %v, want %q, %v", tt.rel, dir, page, tt.dir, tt.page)
+		}
+	}
+}
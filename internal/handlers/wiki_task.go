@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"lark-integration-skill/internal/models"
+
+	"github.com/gin-gonic/gin"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkwiki "github.com/larksuite/oapi-sdk-go/v3/service/wiki/v2"
+)
+
+// Outcomes of a move-docs-to-wiki request
+const (
+	MoveStatusMoved           = "moved"
+	MoveStatusProcessing      = "processing"
+	MoveStatusFailed          = "failed"
+	MoveStatusApprovalPending = "approval_pending"
+)
+
+// Wiki task statuses as reported by this service
+const (
+	TaskStatusProcessing = "processing"
+	TaskStatusSucceeded  = "succeeded"
+	TaskStatusFailed     = "failed"
+)
+
+const (
+	defaultWikiTaskWait = 30 * time.Second
+	maxWikiTaskWait     = 120 * time.Second
+	wikiTaskPollEvery   = 2 * time.Second
+)
+
+// GetWikiTask reports the status of an async move task and the nodes it produced
+func (h *WikiHandler) GetWikiTask(c *gin.Context) {
+	taskID := c.Param("task_id")
+	if taskID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Task ID is required"})
+		return
+	}
+
+	task, err := h.getWikiMoveTask(context.Background(), taskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data:   task,
+	})
+}
+
+// moveDocToWiki issues a single MoveDocsToWiki call and classifies the outcome
+func (h *WikiHandler) moveDocToWiki(ctx context.Context, spaceID, parentWikiToken, objType, objToken string, apply bool) (models.MoveDocsToWikiResponse, error) {
+	bodyBuilder := larkwiki.NewMoveDocsToWikiSpaceNodeReqBodyBuilder().
+		ObjType(objType).
+		ObjToken(objToken).
+		Apply(apply)
+
+	if parentWikiToken != "" {
+		bodyBuilder.ParentWikiToken(parentWikiToken)
+	}
+
+	input := larkwiki.NewMoveDocsToWikiSpaceNodeReqBuilder().
+		SpaceId(spaceID).
+		Body(bodyBuilder.Build()).
+		Build()
+
+	resp, err := h.Client.Client.Wiki.SpaceNode.MoveDocsToWiki(ctx, input)
+	if err != nil {
+		return models.MoveDocsToWikiResponse{}, err
+	}
+	if !resp.Success() {
+		return models.MoveDocsToWikiResponse{}, fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+	}
+
+	data := models.MoveDocsToWikiResponse{
+		WikiToken: larkcore.StringValue(resp.Data.WikiToken),
+		TaskID:    larkcore.StringValue(resp.Data.TaskId),
+		Applied:   larkcore.BoolValue(resp.Data.Applied),
+	}
+
+	switch {
+	case data.WikiToken != "":
+		data.Status = MoveStatusMoved
+	case data.TaskID != "":
+		data.Status = MoveStatusProcessing
+	case data.Applied:
+		data.Status = MoveStatusApprovalPending
+	default:
+		data.Status = MoveStatusFailed
+	}
+
+	return data, nil
+}
+
+// getWikiMoveTask fetches a move task and summarizes the per-document results
+func (h *WikiHandler) getWikiMoveTask(ctx context.Context, taskID string) (models.WikiTaskResponse, error) {
+	input := larkwiki.NewGetTaskReqBuilder().
+		TaskId(taskID).
+		TaskType("move").
+		Build()
+
+	resp, err := h.Client.Client.Wiki.Task.Get(ctx, input)
+	if err != nil {
+		return models.WikiTaskResponse{}, err
+	}
+	if !resp.Success() {
+		return models.WikiTaskResponse{}, fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+	}
+
+	task := models.WikiTaskResponse{
+		TaskID:  taskID,
+		Status:  TaskStatusProcessing,
+		Results: []models.WikiMoveResult{},
+	}
+	if resp.Data.Task == nil {
+		return task, nil
+	}
+
+	succeeded, failed := 0, 0
+	for _, r := range resp.Data.Task.MoveResult {
+		result := models.WikiMoveResult{
+			StatusCode: larkcore.IntValue(r.Status),
+			StatusMsg:  larkcore.StringValue(r.StatusMsg),
+		}
+		if r.Node != nil {
+			result.NodeToken = larkcore.StringValue(r.Node.NodeToken)
+			result.ObjToken = larkcore.StringValue(r.Node.ObjToken)
+			result.ObjType = larkcore.StringValue(r.Node.ObjType)
+			result.Title = larkcore.StringValue(r.Node.Title)
+		}
+
+		// 0 means moved, -1 means still moving, anything else is a failure
+		switch result.StatusCode {
+		case 0:
+			result.Status = TaskStatusSucceeded
+			succeeded++
+		case -1:
+			result.Status = TaskStatusProcessing
+		default:
+			result.Status = TaskStatusFailed
+			failed++
+		}
+		task.Results = append(task.Results, result)
+	}
+
+	if n := len(task.Results); n > 0 && succeeded+failed == n {
+		task.Status = TaskStatusSucceeded
+		if failed > 0 {
+			task.Status = TaskStatusFailed
+		}
+	}
+
+	return task, nil
+}
+
+// waitForWikiTask polls a move task until it leaves the processing state or the timeout elapses.
+// On timeout it returns the last observed (still processing) task without an error.
+func (h *WikiHandler) waitForWikiTask(ctx context.Context, taskID string, timeout time.Duration) (models.WikiTaskResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(wikiTaskPollEvery)
+	defer ticker.Stop()
+
+	for {
+		task, err := h.getWikiMoveTask(ctx, taskID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return models.WikiTaskResponse{TaskID: taskID, Status: TaskStatusProcessing, Results: []models.WikiMoveResult{}}, nil
+			}
+			return task, err
+		}
+		if task.Status != TaskStatusProcessing {
+			return task, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return task, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func moveStatusFromTask(task models.WikiTaskResponse) string {
+	switch task.Status {
+	case TaskStatusSucceeded:
+		return MoveStatusMoved
+	case TaskStatusFailed:
+		return MoveStatusFailed
+	default:
+		return MoveStatusProcessing
+	}
+}
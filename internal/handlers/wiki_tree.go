@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"lark-integration-skill/internal/models"
+
+	"github.com/gin-gonic/gin"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkwiki "github.com/larksuite/oapi-sdk-go/v3/service/wiki/v2"
+)
+
+// maxTreeConcurrency bounds the number of in-flight node list requests while walking a space
+const maxTreeConcurrency = 5
+
+// GetWikiSpaceTree walks every level of a space (or of the subtree under root) and returns it nested
+func (h *WikiHandler) GetWikiSpaceTree(c *gin.Context) {
+	spaceID := c.Param("space_id")
+	if spaceID == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "Space ID is required"})
+		return
+	}
+
+	root := c.Query("root")
+	maxDepth := 0 // Unlimited
+	if maxDepthStr := c.Query("max_depth"); maxDepthStr != "" {
+		depth, err := strconv.Atoi(maxDepthStr)
+		if err != nil || depth < 0 {
+			c.JSON(http.StatusBadRequest, models.APIResponse{Status: "error", Message: "max_depth must be a non-negative integer"})
+			return
+		}
+		maxDepth = depth
+	}
+
+	nodes, count, err := h.walkWikiTree(context.Background(), spaceID, root, maxDepth)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Status: "success",
+		Data: models.WikiTreeResponse{
+			SpaceID:   spaceID,
+			Root:      root,
+			NodeCount: count,
+			Nodes:     nodes,
+		},
+	})
+}
+
+// walkWikiTree lists the nodes under parentToken down to maxDepth levels (0 for no limit),
+// fetching sibling subtrees concurrently. It returns the nested nodes and the total node count.
+func (h *WikiHandler) walkWikiTree(ctx context.Context, spaceID, parentToken string, maxDepth int) ([]*models.WikiTreeNode, int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w := &wikiTreeWalker{
+		h:        h,
+		spaceID:  spaceID,
+		maxDepth: maxDepth,
+		sem:      make(chan struct{}, maxTreeConcurrency),
+		cancel:   cancel,
+	}
+
+	nodes := w.walk(ctx, parentToken, 1)
+	if w.err != nil {
+		return nil, 0, w.err
+	}
+	return nodes, int(w.count.Load()), nil
+}
+
+type wikiTreeWalker struct {
+	h        *WikiHandler
+	spaceID  string
+	maxDepth int
+	sem      chan struct{}
+	count    atomic.Int64
+
+	errOnce sync.Once
+	err     error
+	cancel  context.CancelFunc
+}
+
+func (w *wikiTreeWalker) fail(err error) {
+	w.errOnce.Do(func() {
+		w.err = err
+		w.cancel()
+	})
+}
+
+func (w *wikiTreeWalker) walk(ctx context.Context, parentToken string, depth int) []*models.WikiTreeNode {
+	// Only the API call holds a semaphore slot, so waiting on children can't deadlock
+	select {
+	case w.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil
+	}
+	children, err := w.h.listAllChildNodes(ctx, w.spaceID, parentToken)
+	<-w.sem
+	if err != nil {
+		w.fail(err)
+		return nil
+	}
+
+	nodes := make([]*models.WikiTreeNode, 0, len(children))
+	var wg sync.WaitGroup
+	for _, child := range children {
+		node := toWikiTreeNode(child)
+		nodes = append(nodes, node)
+		w.count.Add(1)
+
+		if !node.HasChild || (w.maxDepth > 0 && depth >= w.maxDepth) {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			node.Children = w.walk(ctx, node.NodeToken, depth+1)
+		}()
+	}
+	wg.Wait()
+
+	return nodes
+}
+
+// listAllChildNodes pages through the direct children of a node (or of the space root when parentToken is empty)
+func (h *WikiHandler) listAllChildNodes(ctx context.Context, spaceID, parentToken string) ([]*larkwiki.Node, error) {
+	var nodes []*larkwiki.Node
+	pageToken := ""
+
+	for {
+		builder := larkwiki.NewListSpaceNodeReqBuilder().
+			SpaceId(spaceID).
+			PageSize(50)
+
+		if parentToken != "" {
+			builder.ParentNodeToken(parentToken)
+		}
+		if pageToken != "" {
+			builder.PageToken(pageToken)
+		}
+
+		resp, err := h.Client.Client.Wiki.SpaceNode.List(ctx, builder.Build())
+		if err != nil {
+			return nil, err
+		}
+		if !resp.Success() {
+			return nil, fmt.Errorf("Lark API Error: %d - %s", resp.Code, resp.Msg)
+		}
+
+		nodes = append(nodes, resp.Data.Items...)
+
+		if !larkcore.BoolValue(resp.Data.HasMore) || larkcore.StringValue(resp.Data.PageToken) == "" {
+			return nodes, nil
+		}
+		pageToken = *resp.Data.PageToken
+	}
+}
+
+func toWikiTreeNode(n *larkwiki.Node) *models.WikiTreeNode {
+	return &models.WikiTreeNode{
+		Title:     larkcore.StringValue(n.Title),
+		NodeToken: larkcore.StringValue(n.NodeToken),
+		ObjToken:  larkcore.StringValue(n.ObjToken),
+		ObjType:   larkcore.StringValue(n.ObjType),
+		NodeType:  larkcore.StringValue(n.NodeType),
+		HasChild:  larkcore.BoolValue(n.HasChild),
+	}
+}
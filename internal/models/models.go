@@ -59,7 +59,8 @@ type CreateDocBlockRequest struct {
 }
 
 type CreateDocBlockResponse struct {
-	Blocks []*larkdocx.Block `json:"blocks"`
+	Blocks             []*larkdocx.Block `json:"blocks"`
+	DocumentRevisionId *int              `json:"document_revision_id"`
 }
 
 type UpdateDocBlockRequest struct {
@@ -67,7 +68,8 @@ type UpdateDocBlockRequest struct {
 }
 
 type UpdateDocBlockResponse struct {
-	Block *larkdocx.Block `json:"block"`
+	Block              *larkdocx.Block `json:"block"`
+	DocumentRevisionId *int            `json:"document_revision_id"`
 }
 
 type GetDocBlockRequest struct {
@@ -107,6 +109,118 @@ type ConvertContentToBlocksResponse struct {
 	Blocks []*larkdocx.Block `json:"blocks"`
 }
 
+// Export Models
+type CreateExportJobRequest struct {
+	FileExtension string `json:"file_extension" binding:"required"` // "pdf" or "docx" for docx; "xlsx" or "csv" for sheet
+	Type          string `json:"type"`                              // "docx" or "sheet", default "docx"
+	SubID         string `json:"sub_id"`                            // Sheet ID; required for csv, which exports a single sheet
+}
+
+type ExportJobResponse struct {
+	JobID         string `json:"job_id"`
+	DocToken      string `json:"doc_token"`
+	Type          string `json:"type"`
+	FileExtension string `json:"file_extension"`
+	Status        string `json:"status"` // "processing", "succeeded" or "failed"
+	FileToken     string `json:"file_token,omitempty"`
+	FileName      string `json:"file_name,omitempty"`
+	FileSize      int    `json:"file_size,omitempty"`
+	ErrorMsg      string `json:"error_msg,omitempty"`
+	CreateTime    int64  `json:"create_time"` // Unix timestamp
+}
+
+// Version Models
+type DocRevisionResponse struct {
+	DocToken   string `json:"doc_token"`
+	Title      string `json:"title"`
+	RevisionID int    `json:"revision_id"` // Latest revision of the document
+}
+
+type CreateDocVersionRequest struct {
+	Name    string `json:"name" binding:"required"`
+	ObjType string `json:"obj_type"` // "docx" or "sheet", default "docx"
+}
+
+type DocVersion struct {
+	VersionID  string `json:"version_id"`
+	Name       string `json:"name"`
+	ObjType    string `json:"obj_type"`
+	CreatorID  string `json:"creator_id"`
+	OwnerID    string `json:"owner_id"`
+	CreateTime string `json:"create_time"`
+	UpdateTime string `json:"update_time"`
+	Status     string `json:"status"`
+}
+
+type DocVersionListResponse struct {
+	Items     []DocVersion `json:"items"`
+	HasMore   bool         `json:"has_more"`
+	PageToken string       `json:"page_token"`
+}
+
+// Snapshot Models
+type DocOperationResponse struct {
+	OperationID int64  `json:"operation_id"`
+	Kind        string `json:"kind"`     // "create", "update" or "delete"
+	BlockID     string `json:"block_id"` // Parent block for create/delete, target block for update
+	Index       int    `json:"index"`    // First affected child index (create/delete)
+	BlockCount  int    `json:"block_count"`
+	CreateTime  int64  `json:"create_time"` // Unix timestamp
+}
+
+type DocOperationListResponse struct {
+	Items []DocOperationResponse `json:"items"` // Most recent first
+}
+
+type UndoDocRequest struct {
+	Steps int `json:"steps"` // Number of operations to revert, default 1
+}
+
+type UndoDocResponse struct {
+	Reverted []DocOperationResponse `json:"reverted"`
+}
+
+// Outline Models
+type OutlineItem struct {
+	Level    int            `json:"level"` // Heading level, 1-9
+	Text     string         `json:"text"`
+	BlockID  string         `json:"block_id"`
+	Anchor   string         `json:"anchor"` // Link to the heading within the document
+	Children []*OutlineItem `json:"children,omitempty"`
+}
+
+type DocOutlineResponse struct {
+	DocToken string         `json:"doc_token"`
+	Items    []*OutlineItem `json:"items"`
+}
+
+type RefreshTOCRequest struct {
+	Title    string `json:"title"`     // Optional: TOC heading text, default "Table of Contents"
+	MaxLevel int    `json:"max_level"` // Optional: Deepest heading level to include, default 3
+}
+
+type RefreshTOCResponse struct {
+	BlockID  string `json:"block_id"` // The TOC callout block
+	Replaced bool   `json:"replaced"` // Whether an existing TOC was replaced
+	Headings int    `json:"headings"`
+}
+
+// Chunk Models
+type DocChunk struct {
+	Index        int      `json:"index"`
+	HeadingPath  []string `json:"heading_path"` // Enclosing headings, outermost first
+	StartBlockID string   `json:"start_block_id"`
+	EndBlockID   string   `json:"end_block_id"`
+	Text         string   `json:"text"`   // Markdown
+	Tokens       int      `json:"tokens"` // Estimated token count
+}
+
+type DocChunksResponse struct {
+	DocToken  string     `json:"doc_token"`
+	MaxTokens int        `json:"max_tokens"`
+	Chunks    []DocChunk `json:"chunks"`
+}
+
 // Wiki Models
 type CreateWikiNodeRequest struct {
 	SpaceID    string `json:"space_id" binding:"required"`
@@ -141,6 +255,18 @@ type MoveWikiNodeResponse struct {
 	ObjToken  string `json:"obj_token"`
 }
 
+type CopyWikiNodeRequest struct {
+	TargetSpaceID     string `json:"target_space_id"`     // Optional: Defaults to the source space
+	TargetParentToken string `json:"target_parent_token"` // Optional: Defaults to the target space root
+	Title             string `json:"title"`               // Optional: Title of the copied root node
+	Recursive         bool   `json:"recursive"`           // Copy the whole subtree, keeping its hierarchy
+}
+
+type CopyWikiNodeResponse struct {
+	Node        *WikiTreeNode `json:"node"` // The new root node, with copied descendants when recursive
+	CopiedCount int           `json:"copied_count"`
+}
+
 type UpdateWikiNodeTitleRequest struct {
 	Title string `json:"title" binding:"required"`
 }
@@ -153,25 +279,71 @@ type MoveDocsToWikiRequest struct {
 	ParentWikiToken string `json:"parent_wiki_token"` // Optional
 	ObjType         string `json:"obj_type" binding:"required"`
 	ObjToken        string `json:"obj_token" binding:"required"`
-	Apply           bool   `json:"apply"` // Optional
+	Apply           bool   `json:"apply"`        // Optional: Request the owner's approval if the app lacks permission to move
+	Wait            bool   `json:"wait"`         // Optional: Block until an async move finishes
+	WaitTimeout     int    `json:"wait_timeout"` // Optional: Seconds to wait, default 30, max 120
 }
 
 type MoveDocsToWikiResponse struct {
-	WikiToken string `json:"wiki_token"`
-	TaskID    string `json:"task_id"`
-	Applied   bool   `json:"applied"`
+	Status    string            `json:"status"` // "moved", "processing", "failed" or "approval_pending"
+	WikiToken string            `json:"wiki_token"`
+	TaskID    string            `json:"task_id"`
+	Applied   bool              `json:"applied"`
+	Task      *WikiTaskResponse `json:"task,omitempty"` // Task result when waiting on an async move
+}
+
+type WikiMoveResult struct {
+	NodeToken  string `json:"node_token"`
+	ObjToken   string `json:"obj_token"`
+	ObjType    string `json:"obj_type"`
+	Title      string `json:"title"`
+	Status     string `json:"status"` // "succeeded", "processing" or "failed"
+	StatusCode int    `json:"status_code"`
+	StatusMsg  string `json:"status_msg"`
+}
+
+type WikiTaskResponse struct {
+	TaskID  string           `json:"task_id"`
+	Status  string           `json:"status"` // "processing", "succeeded" or "failed"
+	Results []WikiMoveResult `json:"results"`
 }
 
 type WikiSearchRequest struct {
-	Query     string `json:"query" binding:"required"`
-	PageSize  int    `json:"page_size"`
-	PageToken string `json:"page_token"`
+	Query         string   `json:"query" binding:"required"`
+	PageSize      int      `json:"page_size"`
+	PageToken     string   `json:"page_token"`
+	SpaceIDs      []string `json:"space_ids"`      // Optional: Only search these spaces (wiki results only)
+	DocTypes      []string `json:"doc_types"`      // Optional: e.g. "DOCX", "SHEET", "BITABLE"
+	OwnerIDs      []string `json:"owner_ids"`      // Optional: Owner open_ids
+	OnlyTitle     bool     `json:"only_title"`     // Match titles only
+	SortType      string   `json:"sort_type"`      // Optional: Passed through to Lark, e.g. "EDIT_TIME"
+	UpdatedAfter  int64    `json:"updated_after"`  // Optional: Unix timestamp, inclusive
+	UpdatedBefore int64    `json:"updated_before"` // Optional: Unix timestamp, inclusive
+	MaxResults    int      `json:"max_results"`    // Optional: Follow page tokens until this many results (max 200)
+}
+
+type WikiSearchResult struct {
+	Title              string   `json:"title"`
+	TitleHighlighted   string   `json:"title_highlighted"`
+	Token              string   `json:"token"`
+	EntityType         string   `json:"entity_type"` // "DOC" or "WIKI"
+	DocType            string   `json:"doc_type"`
+	URL                string   `json:"url"`
+	OwnerID            string   `json:"owner_id"`
+	OwnerName          string   `json:"owner_name"`
+	EditUserName       string   `json:"edit_user_name"`
+	Snippet            string   `json:"snippet"`
+	SnippetHighlighted string   `json:"snippet_highlighted"`
+	Highlights         []string `json:"highlights"` // Matched terms
+	CreateTime         int64    `json:"create_time"`
+	UpdateTime         int64    `json:"update_time"`
 }
 
 type WikiSearchResponse struct {
-	Items     interface{} `json:"items"`
-	HasMore   bool        `json:"has_more"`
-	PageToken string      `json:"page_token"`
+	Items     []WikiSearchResult `json:"items"`
+	Total     int                `json:"total"` // Lark's total match count, before local date filtering
+	HasMore   bool               `json:"has_more"`
+	PageToken string             `json:"page_token"`
 }
 
 type WikiNodeResponse struct {
@@ -181,6 +353,592 @@ type WikiNodeResponse struct {
 	URL       string `json:"url"`
 }
 
+type WikiTreeNode struct {
+	Title     string          `json:"title"`
+	NodeToken string          `json:"node_token"`
+	ObjToken  string          `json:"obj_token"`
+	ObjType   string          `json:"obj_type"`
+	NodeType  string          `json:"node_type"` // "origin" or "shortcut"
+	HasChild  bool            `json:"has_child"`
+	Children  []*WikiTreeNode `json:"children,omitempty"` // Empty when HasChild is set but max_depth was reached
+}
+
+// Wiki Cleanup Models
+type DeleteWikiNodeResponse struct {
+	Deleted      []string `json:"deleted"`                 // Node tokens, descendants first
+	ConfirmToken string   `json:"confirm_token,omitempty"` // Set when a subtree delete needs confirmation
+	SubtreeSize  int      `json:"subtree_size"`            // Nodes that would be or were deleted, including the root
+}
+
+type ArchiveWikiNodeRequest struct {
+	ArchiveParent string `json:"archive_parent"` // Optional: Wiki node to archive under, default the configured archive parent
+}
+
+type ArchiveWikiNodeResponse struct {
+	NodeToken   string `json:"node_token"`
+	SpaceID     string `json:"space_id"`
+	ParentToken string `json:"parent_token"`
+	Title       string `json:"title"`
+}
+
+// Wiki Space Models
+type WikiSpace struct {
+	SpaceID     string `json:"space_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	SpaceType   string `json:"space_type"`   // "team" or "person"
+	Visibility  string `json:"visibility"`   // "public" or "private"
+	OpenSharing string `json:"open_sharing"` // "open" or "closed"
+}
+
+type WikiSpaceListResponse struct {
+	Items     []WikiSpace `json:"items"`
+	HasMore   bool        `json:"has_more"`
+	PageToken string      `json:"page_token"`
+}
+
+type CreateWikiSpaceRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type WikiSpaceSettings struct {
+	CreateSetting   string `json:"create_setting,omitempty"`   // "admin_and_member" or "admin"
+	SecuritySetting string `json:"security_setting,omitempty"` // "allow" or "not_allow"
+	CommentSetting  string `json:"comment_setting,omitempty"`  // "allow" or "not_allow"
+}
+
+type WikiSpaceMember struct {
+	MemberType string `json:"member_type"` // "openid", "userid", "email", "openchat", "opendepartmentid", "unionid"
+	MemberID   string `json:"member_id"`
+	MemberRole string `json:"member_role"` // "admin" or "member"
+	Type       string `json:"type,omitempty"`
+}
+
+type WikiSpaceMemberListResponse struct {
+	Items     []WikiSpaceMember `json:"items"`
+	HasMore   bool              `json:"has_more"`
+	PageToken string            `json:"page_token"`
+}
+
+type AddWikiSpaceMemberRequest struct {
+	MemberType       string `json:"member_type" binding:"required"`
+	MemberID         string `json:"member_id" binding:"required"`
+	MemberRole       string `json:"member_role"` // Default "member"
+	NeedNotification bool   `json:"need_notification"`
+}
+
+type RemoveWikiSpaceMemberRequest struct {
+	MemberType string `json:"member_type"` // Required, in the body or the query string
+	MemberRole string `json:"member_role"` // Default "member"
+}
+
+type ResolveWikiPathRequest struct {
+	Path    string `json:"path" binding:"required"` // Titles separated by "/", e.g. "Engineering/Runbooks/Deploy"
+	Create  bool   `json:"create"`                  // Create missing nodes along the path
+	ObjType string `json:"obj_type"`                // Type of created nodes, default "docx"
+}
+
+type ResolveWikiPathResponse struct {
+	Path    string               `json:"path"`
+	Node    WikiNodeInfoResponse `json:"node"`
+	Created []string             `json:"created,omitempty"` // Titles of nodes created while resolving
+}
+
+type WikiTreeResponse struct {
+	SpaceID   string          `json:"space_id"`
+	Root      string          `json:"root,omitempty"` // Node the tree was walked from; empty for the space root
+	NodeCount int             `json:"node_count"`
+	Nodes     []*WikiTreeNode `json:"nodes"`
+}
+
+// Wiki Sync Models
+type SyncWikiMarkdownRequest struct {
+	Dir             string `json:"dir" binding:"required"` // Directory of Markdown files, relative to the server's wiki files root
+	ParentWikiToken string `json:"parent_wiki_token"`      // Optional: Wiki node to sync under, default the space root
+	ManifestPath    string `json:"manifest_path"`          // Optional: Relative to the wiki files root, default "<dir>/.lark-wiki.json"
+	DeleteRemoved   bool   `json:"delete_removed"`         // Delete nodes whose files no longer exist
+}
+
+// WikiSyncManifest maps paths relative to the synced directory to the wiki nodes created for them
+type WikiSyncManifest struct {
+	SpaceID         string                   `json:"space_id"`
+	ParentWikiToken string                   `json:"parent_wiki_token"`
+	Folders         map[string]WikiSyncEntry `json:"folders"`
+	Files           map[string]WikiSyncEntry `json:"files"`
+}
+
+type WikiSyncEntry struct {
+	NodeToken string `json:"node_token"`
+	ObjToken  string `json:"obj_token"`
+	Hash      string `json:"hash,omitempty"` // SHA-256 of the file content when last written
+}
+
+type WikiSyncResult struct {
+	Path      string `json:"path"`
+	Action    string `json:"action"` // "created", "updated", "unchanged", "deleted" or "failed"
+	NodeToken string `json:"node_token,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type SyncWikiMarkdownResponse struct {
+	Results []WikiSyncResult `json:"results"`
+	Counts  map[string]int   `json:"counts"` // Results per action
+}
+
+// Wiki Markdown Export Models
+type ExportWikiMarkdownRequest struct {
+	Root       string `json:"root"`        // Optional: Node token to export, default the whole space
+	Format     string `json:"format"`      // "zip" (default, streamed back) or "dir"
+	Dir        string `json:"dir"`         // Directory to write into, relative to the server's wiki files root; required for "dir"
+	SkipImages bool   `json:"skip_images"` // Keep image tokens instead of downloading images
+}
+
+type WikiMarkdownFile struct {
+	Path      string `json:"path"` // Relative to the export root
+	Title     string `json:"title"`
+	NodeToken string `json:"node_token"`
+	ObjToken  string `json:"obj_token"`
+	ObjType   string `json:"obj_type"`
+}
+
+type ExportWikiMarkdownResponse struct {
+	Dir     string             `json:"dir"`
+	Files   []WikiMarkdownFile `json:"files"`
+	Images  int                `json:"images"`
+	Skipped []WikiMarkdownFile `json:"skipped"` // Non-docx nodes, which have no Markdown form
+}
+
+// Migration Models
+type CreateMigrationRequest struct {
+	FolderToken     string `json:"folder_token" binding:"required"` // Drive folder to migrate
+	ParentWikiToken string `json:"parent_wiki_token"`               // Optional: Wiki node to migrate under, default the space root
+	Apply           bool   `json:"apply"`                           // Optional: Request owner approval for docs the app can't move
+}
+
+type MigrationFolder struct {
+	FolderToken string `json:"folder_token"`
+	Name        string `json:"name"`
+	WikiToken   string `json:"wiki_token"` // Wiki node standing in for the folder
+	ParentToken string `json:"parent_token"`
+	Listed      bool   `json:"listed"`
+}
+
+type MigrationItem struct {
+	ObjToken    string `json:"obj_token"`
+	ObjType     string `json:"obj_type"`
+	Name        string `json:"name"`
+	FolderToken string `json:"folder_token"`
+	Status      string `json:"status"` // "pending", "processing", "moved", "approval_pending", "failed" or "skipped"
+	WikiToken   string `json:"wiki_token,omitempty"`
+	TaskID      string `json:"task_id,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+type MigrationJob struct {
+	JobID           string            `json:"job_id"`
+	SpaceID         string            `json:"space_id"`
+	FolderToken     string            `json:"folder_token"`
+	ParentWikiToken string            `json:"parent_wiki_token"`
+	Apply           bool              `json:"apply"`
+	Status          string            `json:"status"` // "running", "completed" or "failed"
+	Error           string            `json:"error,omitempty"`
+	Counts          map[string]int    `json:"counts"` // Items per status
+	Folders         []MigrationFolder `json:"folders"`
+	Items           []MigrationItem   `json:"items"`
+	CreateTime      int64             `json:"create_time"` // Unix timestamp
+	UpdateTime      int64             `json:"update_time"`
+}
+
+type MigrationJobListResponse struct {
+	Items []MigrationJob `json:"items"` // Jobs without folder and item details
+}
+
+// Sheet Models
+type SheetInfo struct {
+	SheetID     string `json:"sheet_id"`
+	Title       string `json:"title"`
+	Index       int    `json:"index"`
+	RowCount    int    `json:"row_count"`
+	ColumnCount int    `json:"column_count"`
+	Hidden      bool   `json:"hidden"`
+}
+
+type SheetListResponse struct {
+	Items []SheetInfo `json:"items"`
+}
+
+type AddSheetRequest struct {
+	Title string `json:"title" binding:"required"`
+	Index *int   `json:"index"` // Optional: Position, default last
+}
+
+type SheetRangeResponse struct {
+	Range    string                   `json:"range"`
+	Revision int                      `json:"revision"`
+	Values   [][]interface{}          `json:"values,omitempty"`
+	Headers  []string                 `json:"headers,omitempty"` // With format=records, the header row
+	Records  []map[string]interface{} `json:"records,omitempty"` // With format=records, one map per row keyed by header
+}
+
+type WriteSheetRangeRequest struct {
+	Range  string          `json:"range"` // Optional: Top-left cell such as "B2", default "A1"
+	Values [][]interface{} `json:"values" binding:"required"`
+}
+
+type AppendSheetRowsRequest struct {
+	Values  [][]interface{}          `json:"values"`  // Rows to append
+	Records []map[string]interface{} `json:"records"` // Or rows keyed by the header row
+}
+
+type ClearSheetRangeRequest struct {
+	Range string `json:"range" binding:"required"` // e.g. "A2:D100"
+}
+
+type SheetWriteResponse struct {
+	UpdatedRange   string `json:"updated_range"`
+	UpdatedRows    int    `json:"updated_rows"`
+	UpdatedColumns int    `json:"updated_columns"`
+	UpdatedCells   int    `json:"updated_cells"`
+	Revision       int    `json:"revision"`
+}
+
+// Bitable Models
+type BitableTable struct {
+	TableID  string `json:"table_id"`
+	Name     string `json:"name"`
+	Revision int    `json:"revision"`
+}
+
+type BitableTableListResponse struct {
+	Items []BitableTable `json:"items"`
+}
+
+type BitableField struct {
+	FieldID   string      `json:"field_id"`
+	FieldName string      `json:"field_name"`
+	Type      int         `json:"type"`    // Lark field type, e.g. 1 text, 2 number, 3 single select, 5 date, 11 person
+	UiType    string      `json:"ui_type"` // e.g. "Text", "Progress", "Email"
+	IsPrimary bool        `json:"is_primary"`
+	Property  interface{} `json:"property,omitempty"`
+}
+
+type BitableFieldListResponse struct {
+	Items []BitableField `json:"items"`
+}
+
+type BitableFieldSchema struct {
+	FieldName string                 `json:"field_name" binding:"required"`
+	Type      int                    `json:"type" binding:"required"`
+	Property  map[string]interface{} `json:"property"` // Optional: e.g. {"options": [{"name": "P0"}]} for selects
+}
+
+type CreateBitableTableRequest struct {
+	Name            string               `json:"name" binding:"required"`
+	DefaultViewName string               `json:"default_view_name"`
+	Fields          []BitableFieldSchema `json:"fields"` // Optional: The first field becomes the primary field
+}
+
+type CreateBitableTableResponse struct {
+	TableID       string   `json:"table_id"`
+	DefaultViewID string   `json:"default_view_id"`
+	FieldIDs      []string `json:"field_ids"`
+}
+
+type BitableRecord struct {
+	RecordID         string                 `json:"record_id,omitempty"`
+	Fields           map[string]interface{} `json:"fields"`
+	CreatedTime      int64                  `json:"created_time,omitempty"`       // Unix milliseconds
+	LastModifiedTime int64                  `json:"last_modified_time,omitempty"` // Unix milliseconds
+}
+
+type BitableCondition struct {
+	FieldName string   `json:"field_name" binding:"required"`
+	Operator  string   `json:"operator" binding:"required"` // "is", "isNot", "contains", "doesNotContain", "isEmpty", "isNotEmpty", "isGreater", "isLess", ...
+	Value     []string `json:"value"`
+}
+
+type BitableFilter struct {
+	Conjunction string             `json:"conjunction"` // "and" (default) or "or"
+	Conditions  []BitableCondition `json:"conditions"`
+}
+
+type BitableSort struct {
+	FieldName string `json:"field_name"`
+	Desc      bool   `json:"desc"`
+}
+
+type SearchBitableRecordsRequest struct {
+	ViewID     string         `json:"view_id"`
+	FieldNames []string       `json:"field_names"` // Optional: Only return these fields
+	Filter     *BitableFilter `json:"filter"`
+	Sort       []BitableSort  `json:"sort"`
+	PageSize   int            `json:"page_size"`
+	PageToken  string         `json:"page_token"`
+	MaxResults int            `json:"max_results"` // Optional: Follow page tokens until this many records (max 10000)
+}
+
+type BitableRecordListResponse struct {
+	Items     []BitableRecord `json:"items"`
+	Total     int             `json:"total"`
+	HasMore   bool            `json:"has_more"`
+	PageToken string          `json:"page_token"`
+}
+
+type BatchBitableRecordsRequest struct {
+	Records []BitableRecord `json:"records" binding:"required"` // record_id is required for updates
+}
+
+type BatchDeleteBitableRecordsRequest struct {
+	RecordIDs []string `json:"record_ids" binding:"required"`
+}
+
+type BitableBatchResponse struct {
+	Records []BitableRecord `json:"records"`
+}
+
+type BitableBatchDeleteResponse struct {
+	Deleted []string `json:"deleted"`
+}
+
+type UpsertBitableRecordsRequest struct {
+	KeyField string          `json:"key_field" binding:"required"` // Text, number, single select or phone field that identifies a record
+	Records  []BitableRecord `json:"records" binding:"required"`   // Every record needs a value for the key field
+}
+
+type BitableUpsertResult struct {
+	Key      string `json:"key"`
+	RecordID string `json:"record_id,omitempty"`
+	Action   string `json:"action"` // "created", "updated", "unchanged" or "failed"
+}
+
+type UpsertBitableRecordsResponse struct {
+	Created   int                   `json:"created"`
+	Updated   int                   `json:"updated"`
+	Unchanged int                   `json:"unchanged"`
+	Results   []BitableUpsertResult `json:"results"` // In request order
+}
+
+// Calendar Models
+type CalendarInfo struct {
+	CalendarID  string `json:"calendar_id"`
+	Summary     string `json:"summary"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type"`        // "primary", "shared", "google", "resource", "exchange"
+	Role        string `json:"role"`        // The app's access: "free_busy_reader", "reader", "writer", "owner"
+	Permissions string `json:"permissions"` // "private", "show_only_free_busy", "public"
+}
+
+type CalendarListResponse struct {
+	Items []CalendarInfo `json:"items"`
+}
+
+type CalendarAttendee struct {
+	Type        string `json:"type"`            // "user" (default), "chat", "resource" or "third_party"
+	ID          string `json:"id,omitempty"`    // open_id, chat_id or room_id
+	Email       string `json:"email,omitempty"` // Users may be given by email; emails without a Lark user are invited as third_party
+	Optional    bool   `json:"optional,omitempty"`
+	DisplayName string `json:"display_name,omitempty"` // Response only
+	RsvpStatus  string `json:"rsvp_status,omitempty"`  // Response only: "needs_action", "accept", "tentative", "decline"
+}
+
+type CalendarEvent struct {
+	EventID     string             `json:"event_id"`
+	CalendarID  string             `json:"calendar_id"` // The organizer's calendar
+	Summary     string             `json:"summary"`
+	Description string             `json:"description,omitempty"`
+	StartTime   string             `json:"start_time"` // RFC 3339, or a date for all-day events
+	EndTime     string             `json:"end_time"`
+	AllDay      bool               `json:"all_day"`
+	Timezone    string             `json:"timezone,omitempty"`
+	Location    string             `json:"location,omitempty"`
+	MeetingURL  string             `json:"meeting_url,omitempty"`
+	Status      string             `json:"status"` // "tentative", "confirmed", "cancelled"
+	Visibility  string             `json:"visibility,omitempty"`
+	Recurrence  string             `json:"recurrence,omitempty"` // RFC 5545 RRULE
+	AppLink     string             `json:"app_link,omitempty"`
+	Attendees   []CalendarAttendee `json:"attendees,omitempty"`
+}
+
+type CalendarEventListResponse struct {
+	Items []CalendarEvent `json:"items"`
+}
+
+type CreateCalendarEventRequest struct {
+	Summary          string             `json:"summary" binding:"required"`
+	Description      string             `json:"description"`
+	StartTime        string             `json:"start_time" binding:"required"` // RFC 3339, "2006-01-02T15:04" in timezone, or "2006-01-02" for all-day
+	EndTime          string             `json:"end_time" binding:"required"`   // All-day events end on their last day
+	Timezone         string             `json:"timezone"`                      // IANA name, e.g. "Asia/Shanghai"; defaults to the server's calendar timezone
+	Location         string             `json:"location"`
+	Attendees        []CalendarAttendee `json:"attendees"`
+	VideoMeeting     bool               `json:"video_meeting"` // Attach a Lark video meeting link
+	Visibility       string             `json:"visibility"`    // "default", "public" or "private"
+	Recurrence       string             `json:"recurrence"`    // Optional RFC 5545 RRULE, e.g. "FREQ=WEEKLY;COUNT=4"
+	NeedNotification *bool              `json:"need_notification"`
+}
+
+type UpdateCalendarEventRequest struct {
+	Summary          *string            `json:"summary"`
+	Description      *string            `json:"description"`
+	StartTime        *string            `json:"start_time"` // Start and end must be given together
+	EndTime          *string            `json:"end_time"`
+	Timezone         *string            `json:"timezone"`
+	Location         *string            `json:"location"`
+	VideoMeeting     *bool              `json:"video_meeting"`
+	AddAttendees     []CalendarAttendee `json:"add_attendees"`
+	NeedNotification *bool              `json:"need_notification"`
+}
+
+type FreeBusyRequest struct {
+	Users   []string `json:"users" binding:"required"`    // Emails or open IDs
+	TimeMin string   `json:"time_min" binding:"required"` // RFC 3339; the range may span at most 3 months
+	TimeMax string   `json:"time_max" binding:"required"`
+}
+
+type BusySlot struct {
+	StartTime string `json:"start_time"` // RFC 3339
+	EndTime   string `json:"end_time"`
+}
+
+type UserFreeBusy struct {
+	User   string     `json:"user"` // As given in the request
+	UserID string     `json:"user_id"`
+	Busy   []BusySlot `json:"busy"`
+}
+
+type FreeBusyResponse struct {
+	Items []UserFreeBusy `json:"items"`
+}
+
+type FindSlotsRequest struct {
+	Attendees         []string         `json:"attendees" binding:"required"` // Emails or open IDs; every slot has them all free
+	OptionalAttendees []string         `json:"optional_attendees"`           // Slots where more of them are free rank higher
+	DurationMinutes   int              `json:"duration_minutes" binding:"required"`
+	StartDate         string           `json:"start_date" binding:"required"` // "2006-01-02", inclusive
+	EndDate           string           `json:"end_date" binding:"required"`   // "2006-01-02", inclusive; at most 60 days after start_date
+	WorkStart         string           `json:"work_start"`                    // "15:04", default "09:00"
+	WorkEnd           string           `json:"work_end"`                      // "15:04", default "18:00"
+	Timezone          string           `json:"timezone"`                      // IANA name for the dates and working hours
+	IncludeWeekends   bool             `json:"include_weekends"`
+	StepMinutes       int              `json:"step_minutes"` // Candidate start times are this far apart, default 30
+	MaxResults        int              `json:"max_results"`  // Default 5, max 50
+	Book              *BookSlotRequest `json:"book"`         // Optional: Create an event in the top slot
+}
+
+type BookSlotRequest struct {
+	CalendarID   string `json:"calendar_id"` // Default "primary"
+	Summary      string `json:"summary" binding:"required"`
+	Description  string `json:"description"`
+	Location     string `json:"location"`
+	VideoMeeting bool   `json:"video_meeting"`
+}
+
+type FreeSlot struct {
+	StartTime   string   `json:"start_time"` // RFC 3339 in the request's timezone
+	EndTime     string   `json:"end_time"`
+	Available   []string `json:"available,omitempty"`   // Optional attendees who are free
+	Unavailable []string `json:"unavailable,omitempty"` // Optional attendees who are busy
+}
+
+type FindSlotsResponse struct {
+	Slots  []FreeSlot     `json:"slots"` // Best first
+	Booked *CalendarEvent `json:"booked,omitempty"`
+}
+
+// Message Models
+type MessageContent struct {
+	MsgType  string                 `json:"msg_type"`  // "text" (default), "post", "image", "file" or "interactive"
+	Text     string                 `json:"text"`      // text; for post, Markdown used as the post body
+	Title    string                 `json:"title"`     // post title
+	Post     map[string]interface{} `json:"post"`      // post content per locale, e.g. {"zh_cn": {"title": ..., "content": [[...]]}}; a bare {"title", "content"} is used as zh_cn
+	ImageKey string                 `json:"image_key"` // image, from POST /messages/images
+	FileKey  string                 `json:"file_key"`  // file, from POST /messages/files
+	Card     map[string]interface{} `json:"card"`      // interactive card JSON
+	Template string                 `json:"template"`  // interactive card rendered from a card template with data; implies msg_type interactive
+	Data     map[string]interface{} `json:"data"`      // values for the template's placeholders
+}
+
+type SendMessageRequest struct {
+	ReceiveID     string `json:"receive_id" binding:"required"`
+	ReceiveIDType string `json:"receive_id_type"` // "open_id", "user_id", "union_id", "email" or "chat_id"; guessed from receive_id when empty
+	UUID          string `json:"uuid"`            // Optional idempotency key; repeating it within an hour sends nothing new
+	MessageContent
+}
+
+type ReplyMessageRequest struct {
+	ReplyInThread bool   `json:"reply_in_thread"`
+	UUID          string `json:"uuid"`
+	MessageContent
+}
+
+type SentMessage struct {
+	MessageID  string `json:"message_id"`
+	ChatID     string `json:"chat_id"`
+	RootID     string `json:"root_id,omitempty"`
+	ParentID   string `json:"parent_id,omitempty"`
+	ThreadID   string `json:"thread_id,omitempty"`
+	MsgType    string `json:"msg_type"`
+	CreateTime string `json:"create_time"` // RFC 3339
+}
+
+type UploadImageResponse struct {
+	ImageKey string `json:"image_key"`
+}
+
+type UploadFileResponse struct {
+	FileKey string `json:"file_key"`
+}
+
+type ChatMessage struct {
+	MessageID  string            `json:"message_id"`
+	ChatID     string            `json:"chat_id"`
+	RootID     string            `json:"root_id,omitempty"`
+	ParentID   string            `json:"parent_id,omitempty"`
+	ThreadID   string            `json:"thread_id,omitempty"`
+	MsgType    string            `json:"msg_type"`
+	SenderID   string            `json:"sender_id"`             // open_id for users, app_id for apps
+	SenderType string            `json:"sender_type"`           // "user", "app", "anonymous" or "unknown"
+	SenderName string            `json:"sender_name,omitempty"` // Empty when the app cannot see the sender
+	Text       string            `json:"text"`                  // Plain-text rendering of the content, with mentions as @Name
+	Resources  []MessageResource `json:"resources,omitempty"`   // Images and files, for GET /messages/:message_id/resources/:file_key
+	CreateTime string            `json:"create_time"`           // RFC 3339
+	Updated    bool              `json:"updated,omitempty"`
+	Deleted    bool              `json:"deleted,omitempty"`
+}
+
+type MessageResource struct {
+	Type string `json:"type"` // "image" or "file"
+	Key  string `json:"key"`
+	Name string `json:"name,omitempty"`
+}
+
+type ChatHistoryResponse struct {
+	Items     []ChatMessage `json:"items"`
+	HasMore   bool          `json:"has_more"`
+	PageToken string        `json:"page_token,omitempty"`
+}
+
+type MessageThreadResponse struct {
+	ThreadID string        `json:"thread_id,omitempty"` // Empty when the replies were followed through parent_id instead
+	Items    []ChatMessage `json:"items"`               // Oldest first
+}
+
+type CardTemplateInfo struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Variables   []string `json:"variables"` // Placeholders the template uses
+	Source      string   `json:"source"`    // "builtin" or the file it was loaded from
+}
+
+type CardTemplateListResponse struct {
+	Items []CardTemplateInfo `json:"items"`
+}
+
+type RenderCardRequest struct {
+	Data map[string]interface{} `json:"data"`
+}
+
 // Common Response
 type APIResponse struct {
 	Status  string      `json:"status"`